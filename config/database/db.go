@@ -6,39 +6,27 @@ import (
 	"log"
 	"time"
 
-	"io/ioutil"
-	"os"
-	"strings"
+	"w3/gc3/config/settings"
 
-	"github.com/joho/godotenv"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var Pool *pgxpool.Pool
 
-func InitDB(){
-	// Load environment variables from .env file
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatalf("Error loading .env file: %v", err)
-	}
-
-	// retrieve password from .env
-	password := os.Getenv("DB_PASSWORD")
-	if password == "" {
-		log.Fatalf("Environment variable PASSWORD is not set")
-	}
-
+func InitDB(cfg settings.DatabaseConfig){
 	// parse the config
-	connStr := fmt.Sprintf("postgresql://postgres.bwbhnlivrbreoqhrlreg:%s@aws-0-ap-southeast-1.pooler.supabase.com:6543/postgres", password)
-
-	config, err := pgxpool.ParseConfig(connStr)
+	config, err := pgxpool.ParseConfig(cfg.DSN())
     if err != nil {
         log.Fatalf("Failed to parsing config DB: %v", err)
     }
 
-	config.ConnConfig.ConnectTimeout = 5 * time.Second
+	// pool sizing and timeouts
+	config.MaxConns = cfg.MaxConns
+	config.MinConns = cfg.MinConns
+	config.MaxConnLifetime = time.Duration(cfg.MaxConnLifetime)
+	config.MaxConnIdleTime = time.Duration(cfg.MaxConnIdleTime)
+	config.ConnConfig.ConnectTimeout = time.Duration(cfg.ConnectTimeout)
 	
 	// Add AfterConnect to clean up prepared statements or session state
 	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
@@ -64,79 +52,6 @@ func InitDB(){
 	fmt.Println("Database connected")
 }
 
-func MigrateData(){
-	// use recover to handle any potential panics
-	defer HandlePanic()
-
-	// create a context with a timeout (e.g., 30 seconds)
-	ctx, cancel := context.WithTimeout(context.Background(), 30_000_000_000)
-	defer cancel()
-
-	// read the filename from the first argument
-	filename := "config/database/ddl.sql"
-
-	// read SQL commands from the file with the given filename
-	sqlCommands, err := ReadSQLCommands(filename)
-
-	// connect to the DB
-	InitDB();
-
-	if err != nil {
-		panic(err)
-	}
-
-	defer CloseDB();
-
-	// execute SQL commands
-	err = ExecuteSQLCommands(ctx, Pool, sqlCommands)
-	if err != nil {
-		panic(err)
-	}
-
-	// output successful table creation and population
-	fmt.Println("All Tables Created and Populated Successfully!")
-}
-
-// func to handle panic using recover
-func HandlePanic(){
-	if r := recover(); r != nil {
-		fmt.Println("Recovered from panic", r)
-	}
-}
-
-// function to read SQL commands from a file
-func ReadSQLCommands(filename string) (string, error) {
-	data, err := ioutil.ReadFile(filename)
-	if err != nil {
-		return "", err
-	}
-
-	return string(data), nil
-}
-
-// function to execute SQL commands on the db
-func ExecuteSQLCommands(ctx context.Context, db *pgxpool.Pool, commands string) error {
-	statements := strings.Split(commands, ";") // split SQL commands by semicolon
-	for _, stmt := range statements {
-		stmt = strings.TrimSpace(stmt)		   // Trim whitespace
-		if stmt != "" {
-			_, err := db.Exec(ctx, stmt)
-			if err != nil {
-				return fmt.Errorf("failed to execute statement %q: %w", stmt, err)
-			}
-		}
-	}
-	return nil
-}
-
 func CloseDB() {
     Pool.Close()
 }
-
-func ResetDB() {
-    if Pool != nil {
-        fmt.Println("Resetting the database connection pool...")
-        Pool.Close()
-    }
-    InitDB() // Reinitialize the pool
-}
\ No newline at end of file
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// arbitrary key for pg_advisory_xact_lock so concurrent migrators wait for each other
+const migrationLockKey = 7_261_453_120
+
+// migration files are named <version>_<name>.<up|down>.sql
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one numbered schema change with its up and down scripts
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus tells whether a migration has been applied and when
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// LoadMigrations reads the embedded migration scripts ordered by version
+func LoadMigrations() ([]Migration, error) {
+	return readMigrations(migrationFiles, "migrations")
+}
+
+// readMigrations reads the migration scripts in dir of fsys ordered by version
+func readMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+
+		data, err := fs.ReadFile(fsys, dir+"/"+entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both an up and a down script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// MigrateUp applies every pending migration in order, each in its own transaction
+func MigrateUp(ctx context.Context, db *pgxpool.Pool) ([]Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationsTable(ctx, db); err != nil {
+		return nil, err
+	}
+
+	applied := []Migration{}
+	for _, m := range migrations {
+		done, err := runMigration(ctx, db, m, func(ctx context.Context, tx pgx.Tx, isApplied bool) (bool, error) {
+			if isApplied {
+				return false, nil
+			}
+			if _, err := tx.Exec(ctx, m.Up); err != nil {
+				return false, err
+			}
+			_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.Version, m.Name)
+			return true, err
+		})
+		if err != nil {
+			return applied, fmt.Errorf("failed to apply migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		if done {
+			applied = append(applied, m)
+		}
+	}
+
+	return applied, nil
+}
+
+// MigrateDown rolls back the latest `steps` applied migrations, newest first
+func MigrateDown(ctx context.Context, db *pgxpool.Pool, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, errors.New("steps must be greater than 0")
+	}
+
+	statuses, err := MigrationStatuses(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	rolledBack := []Migration{}
+	for i := len(statuses) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+		m := statuses[i].Migration
+		if statuses[i].AppliedAt == nil {
+			continue
+		}
+
+		done, err := runMigration(ctx, db, m, func(ctx context.Context, tx pgx.Tx, isApplied bool) (bool, error) {
+			if !isApplied {
+				return false, nil
+			}
+			if _, err := tx.Exec(ctx, m.Down); err != nil {
+				return false, err
+			}
+			_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, m.Version)
+			return true, err
+		})
+		if err != nil {
+			return rolledBack, fmt.Errorf("failed to roll back migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		if done {
+			rolledBack = append(rolledBack, m)
+		}
+	}
+
+	return rolledBack, nil
+}
+
+// MigrationStatuses lists every known migration and whether it has been applied
+func MigrationStatuses(ctx context.Context, db *pgxpool.Pool) ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationsTable(ctx, db); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	appliedAt := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		appliedAt[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Migration: m}
+		if at, ok := appliedAt[m.Version]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func ensureMigrationsTable(ctx context.Context, db *pgxpool.Pool) error {
+	_, err := db.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// run step inside a transaction holding the migration lock; step is told whether
+// the migration is currently applied so that concurrent runs stay idempotent
+func runMigration(ctx context.Context, db *pgxpool.Pool, m Migration, step func(context.Context, pgx.Tx, bool) (bool, error)) (bool, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockKey); err != nil {
+		return false, err
+	}
+
+	var isApplied bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, m.Version).Scan(&isApplied)
+	if err != nil {
+		return false, err
+	}
+
+	done, err := step(ctx, tx, isApplied)
+	if err != nil {
+		return false, err
+	}
+	return done, tx.Commit(ctx)
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %d_%s at position %d, want versions numbered from 1 without gaps", m.Version, m.Name, i)
+		}
+	}
+}
+
+func TestReadMigrations(t *testing.T) {
+	file := func(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []string // version_name of each migration
+		wantErr string
+	}{
+		{
+			name: "ordered by version",
+			files: fstest.MapFS{
+				"m/0010_b.up.sql":   file("up b"),
+				"m/0010_b.down.sql": file("down b"),
+				"m/0002_a.up.sql":   file("up a"),
+				"m/0002_a.down.sql": file("down a"),
+			},
+			want: []string{"2_a", "10_b"},
+		},
+		{
+			name:    "invalid name",
+			files:   fstest.MapFS{"m/0001_a.sql": file("")},
+			wantErr: "invalid migration file name",
+		},
+		{
+			name: "conflicting names",
+			files: fstest.MapFS{
+				"m/0001_a.up.sql":   file("up"),
+				"m/0001_b.down.sql": file("down"),
+			},
+			wantErr: "conflicting names",
+		},
+		{
+			name:    "missing down script",
+			files:   fstest.MapFS{"m/0001_a.up.sql": file("up")},
+			wantErr: "must have both an up and a down script",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := readMigrations(tt.files, "m")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, m := range migrations {
+				got = append(got, fmt.Sprintf("%d_%s", m.Version, m.Name))
+				if m.Up == "" || m.Down == "" {
+					t.Errorf("migration %d_%s lost a script", m.Version, m.Name)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("migrations %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMigrateUpAndDown runs every migration up, down and up again against the
+// empty database in TEST_DATABASE_URL, it is skipped when that is not set
+func TestMigrateUpAndDown(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied, err := MigrateUp(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(migrations) {
+		t.Fatalf("applied %d migrations, want %d", len(applied), len(migrations))
+	}
+	if applied, err := MigrateUp(ctx, db); err != nil || len(applied) != 0 {
+		t.Fatalf("second MigrateUp applied %d: %v, want none", len(applied), err)
+	}
+
+	rolledBack, err := MigrateDown(ctx, db, len(migrations))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rolledBack) != len(migrations) || rolledBack[0].Version != migrations[len(migrations)-1].Version {
+		t.Fatalf("rolled back %v, want every migration newest first", rolledBack)
+	}
+	statuses, err := MigrationStatuses(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if s.AppliedAt != nil {
+			t.Errorf("migration %d_%s still applied", s.Version, s.Name)
+		}
+	}
+
+	if applied, err := MigrateUp(ctx, db); err != nil || len(applied) != len(migrations) {
+		t.Fatalf("MigrateUp after rolling back applied %d: %v, want %d", len(applied), err, len(migrations))
+	}
+}
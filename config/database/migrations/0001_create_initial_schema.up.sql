@@ -1,11 +1,5 @@
--- Drop tables if they already exist (for reusability)
-DROP TABLE IF EXISTS comments;
-DROP TABLE IF EXISTS posts;
-DROP TABLE IF EXISTS user_activity_logs;
-DROP TABLE IF EXISTS users;
-
 -- Create the users table
-CREATE TABLE users (
+CREATE TABLE IF NOT EXISTS users (
     id SERIAL PRIMARY KEY,
     full_name VARCHAR(100) NOT NULL,
     email VARCHAR(100) NOT NULL UNIQUE,
@@ -15,7 +9,7 @@ CREATE TABLE users (
 );
 
 -- Create the posts table
-CREATE TABLE posts (
+CREATE TABLE IF NOT EXISTS posts (
     id SERIAL PRIMARY KEY,
     content TEXT NOT NULL,
     image_url VARCHAR(255),
@@ -24,7 +18,7 @@ CREATE TABLE posts (
 );
 
 -- Create the comments table
-CREATE TABLE comments (
+CREATE TABLE IF NOT EXISTS comments (
     id SERIAL PRIMARY KEY,
     content TEXT NOT NULL,
     author_id INT NOT NULL,
@@ -34,26 +28,9 @@ CREATE TABLE comments (
 );
 
 -- Create the user_activity_logs table
-CREATE TABLE user_activity_logs (
+CREATE TABLE IF NOT EXISTS user_activity_logs (
     id SERIAL PRIMARY KEY,
     user_id INT NOT NULL,
     description VARCHAR(255) NOT NULL,
     CONSTRAINT fk_user_logs FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
 );
-
--- Insert seeding data
-INSERT INTO users (full_name, email, username, password, age) VALUES
-('Obie Ananda', 'Obie.Ananda@example.com', 'obie', '11111111', 30),
-('Cristiano Ronaldo', 'CR7@example.com', 'cristiano', '11111111', 25);
-
-INSERT INTO posts (content, image_url, user_id) VALUES
-('How to be a millionaire', 'http://example.com/image1.jpg', 1),
-('Siuuuuuuuuu!', 'http://example.com/image2.jpg', 2);
-
-INSERT INTO comments (content, author_id, post_id) VALUES
-('wow obie!', 2, 1),
-('🐐', 1, 2);
-
-INSERT INTO user_activity_logs (user_id, description) VALUES
-(1, 'Created a post'),
-(2, 'Commented on a post');
\ No newline at end of file
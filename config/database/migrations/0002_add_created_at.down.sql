@@ -0,0 +1,4 @@
+ALTER TABLE user_activity_logs DROP COLUMN IF EXISTS created_at;
+ALTER TABLE comments DROP COLUMN IF EXISTS created_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS created_at;
+ALTER TABLE users DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS post_revisions;
+ALTER TABLE posts DROP COLUMN IF EXISTS edited_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS version;
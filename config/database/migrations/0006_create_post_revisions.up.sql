@@ -0,0 +1,16 @@
+-- Posts can be edited, every replaced version is kept in post_revisions.
+-- posts.version numbers the current version, starting at 1 for the original.
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ;
+
+CREATE TABLE post_revisions (
+    id SERIAL PRIMARY KEY,
+    post_id INT NOT NULL,
+    version INT NOT NULL,
+    content TEXT NOT NULL,
+    image_url VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL, -- when this version was published
+    replaced_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT fk_post_revisions FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE,
+    CONSTRAINT uq_post_revisions_version UNIQUE (post_id, version)
+);
@@ -0,0 +1,17 @@
+-- Comments can be edited by their author or a moderator, every replaced
+-- version is kept in comment_revisions together with who replaced it
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ;
+
+CREATE TABLE comment_revisions (
+    id SERIAL PRIMARY KEY,
+    comment_id INT NOT NULL,
+    version INT NOT NULL,
+    content TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL, -- when this version was published
+    replaced_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    replaced_by INT,
+    CONSTRAINT fk_comment_revisions FOREIGN KEY (comment_id) REFERENCES comments (id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_comment_revisions FOREIGN KEY (replaced_by) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT uq_comment_revisions_version UNIQUE (comment_id, version)
+);
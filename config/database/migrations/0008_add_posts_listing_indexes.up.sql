@@ -0,0 +1,4 @@
+-- Keyset pagination of GET /posts walks (created_at, id) in either direction,
+-- optionally restricted to one author
+CREATE INDEX IF NOT EXISTS idx_posts_created_at_id ON posts (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_posts_user_id_created_at_id ON posts (user_id, created_at, id);
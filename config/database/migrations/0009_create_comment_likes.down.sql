@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_comments_post_id_like_count_id;
+DROP INDEX IF EXISTS idx_comments_post_id_created_at_id;
+DROP TABLE IF EXISTS comment_likes;
+DROP FUNCTION IF EXISTS count_comment_likes();
+ALTER TABLE comments DROP COLUMN IF EXISTS like_count;
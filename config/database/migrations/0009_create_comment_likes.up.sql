@@ -0,0 +1,34 @@
+-- Users can like comments once each. comments.like_count is kept in sync by a
+-- trigger, also when likes disappear through ON DELETE CASCADE, so comments
+-- can be listed by popularity without counting likes on every request.
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS like_count INT NOT NULL DEFAULT 0;
+
+CREATE TABLE comment_likes (
+    comment_id INT NOT NULL,
+    user_id INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (comment_id, user_id),
+    CONSTRAINT fk_comment_likes FOREIGN KEY (comment_id) REFERENCES comments (id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_comment_likes FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_comment_likes_user_id ON comment_likes (user_id);
+
+CREATE FUNCTION count_comment_likes() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE comments SET like_count = like_count + 1 WHERE id = NEW.comment_id;
+    ELSE
+        UPDATE comments SET like_count = like_count - 1 WHERE id = OLD.comment_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_count_comment_likes
+    AFTER INSERT OR DELETE ON comment_likes
+    FOR EACH ROW EXECUTE FUNCTION count_comment_likes();
+
+-- GET /posts/:id/comments pages through (created_at, id) or (like_count, id)
+CREATE INDEX IF NOT EXISTS idx_comments_post_id_created_at_id ON comments (post_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_comments_post_id_like_count_id ON comments (post_id, like_count, id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_comments_parent_id_like_count_id;
+DROP INDEX IF EXISTS idx_comments_parent_id_created_at_id;
+DROP TRIGGER IF EXISTS trg_count_comment_replies ON comments;
+DROP FUNCTION IF EXISTS count_comment_replies();
+ALTER TABLE comments DROP COLUMN IF EXISTS reply_count;
+ALTER TABLE comments DROP COLUMN IF EXISTS depth;
+ALTER TABLE comments DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,26 @@
+-- Comments may reply to another comment of the same post. depth is 0 for
+-- top-level comments and reply_count is kept in sync by a trigger, like
+-- like_count, so threads can be shown without counting replies on every request.
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS parent_id INT
+    CONSTRAINT fk_comment_replies REFERENCES comments (id) ON DELETE CASCADE;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS depth INT NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS reply_count INT NOT NULL DEFAULT 0;
+
+CREATE FUNCTION count_comment_replies() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' AND NEW.parent_id IS NOT NULL THEN
+        UPDATE comments SET reply_count = reply_count + 1 WHERE id = NEW.parent_id;
+    ELSIF TG_OP = 'DELETE' AND OLD.parent_id IS NOT NULL THEN
+        UPDATE comments SET reply_count = reply_count - 1 WHERE id = OLD.parent_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_count_comment_replies
+    AFTER INSERT OR DELETE ON comments
+    FOR EACH ROW EXECUTE FUNCTION count_comment_replies();
+
+-- GET /comments/:id/replies pages through the replies of one comment
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id_created_at_id ON comments (parent_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id_like_count_id ON comments (parent_id, like_count, id);
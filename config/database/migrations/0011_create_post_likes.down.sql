@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS post_likes;
+DROP FUNCTION IF EXISTS count_post_likes();
+ALTER TABLE posts DROP COLUMN IF EXISTS like_count;
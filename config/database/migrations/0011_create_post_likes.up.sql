@@ -0,0 +1,31 @@
+-- Users can like posts once each, posts.like_count is kept in sync by a
+-- trigger the same way as comments.like_count
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS like_count INT NOT NULL DEFAULT 0;
+
+CREATE TABLE post_likes (
+    post_id INT NOT NULL,
+    user_id INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (post_id, user_id),
+    CONSTRAINT fk_post_likes FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_post_likes FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- GET /posts/:id/likes lists the likers of a post, latest first
+CREATE INDEX idx_post_likes_post_id_created_at ON post_likes (post_id, created_at, user_id);
+CREATE INDEX idx_post_likes_user_id ON post_likes (user_id);
+
+CREATE FUNCTION count_post_likes() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE posts SET like_count = like_count + 1 WHERE id = NEW.post_id;
+    ELSE
+        UPDATE posts SET like_count = like_count - 1 WHERE id = OLD.post_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_count_post_likes
+    AFTER INSERT OR DELETE ON post_likes
+    FOR EACH ROW EXECUTE FUNCTION count_post_likes();
@@ -0,0 +1,15 @@
+-- Emoji reactions on comments, a user may use several different reactions
+-- on the same comment but each one only once. The names must match
+-- model.Reactions.
+CREATE TABLE comment_reactions (
+    comment_id INT NOT NULL,
+    user_id INT NOT NULL,
+    reaction VARCHAR(20) NOT NULL
+        CONSTRAINT chk_comment_reactions_reaction CHECK (reaction IN ('thumbs_up', 'heart', 'laugh', 'wow', 'sad', 'angry')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (comment_id, user_id, reaction),
+    CONSTRAINT fk_comment_reactions FOREIGN KEY (comment_id) REFERENCES comments (id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_comment_reactions FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_comment_reactions_user_id ON comment_reactions (user_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS follows;
+DROP FUNCTION IF EXISTS count_follows();
+ALTER TABLE users DROP COLUMN IF EXISTS following_count;
+ALTER TABLE users DROP COLUMN IF EXISTS follower_count;
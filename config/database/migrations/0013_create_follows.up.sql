@@ -0,0 +1,35 @@
+-- Users follow each other; users.follower_count and users.following_count
+-- are kept in sync by a trigger
+ALTER TABLE users ADD COLUMN IF NOT EXISTS follower_count INT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS following_count INT NOT NULL DEFAULT 0;
+
+CREATE TABLE follows (
+    follower_id INT NOT NULL,
+    followee_id INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (follower_id, followee_id),
+    CONSTRAINT chk_follows_self CHECK (follower_id <> followee_id),
+    CONSTRAINT fk_follows_follower FOREIGN KEY (follower_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_follows_followee FOREIGN KEY (followee_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- GET /users/:id/followers and /following list the latest follows first
+CREATE INDEX idx_follows_followee_id_created_at ON follows (followee_id, created_at, follower_id);
+CREATE INDEX idx_follows_follower_id_created_at ON follows (follower_id, created_at, followee_id);
+
+CREATE FUNCTION count_follows() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE users SET following_count = following_count + 1 WHERE id = NEW.follower_id;
+        UPDATE users SET follower_count = follower_count + 1 WHERE id = NEW.followee_id;
+    ELSE
+        UPDATE users SET following_count = following_count - 1 WHERE id = OLD.follower_id;
+        UPDATE users SET follower_count = follower_count - 1 WHERE id = OLD.followee_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_count_follows
+    AFTER INSERT OR DELETE ON follows
+    FOR EACH ROW EXECUTE FUNCTION count_follows();
@@ -0,0 +1,6 @@
+-- DELETE /users/me only marks the account, it is removed for good (taking
+-- everything it owns with it through ON DELETE CASCADE) once the grace period
+-- has passed
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at) WHERE deleted_at IS NOT NULL;
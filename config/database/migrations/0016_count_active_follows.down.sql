@@ -0,0 +1,22 @@
+DROP TRIGGER IF EXISTS trg_count_follows_of_user_delete ON users;
+DROP TRIGGER IF EXISTS trg_count_follows_of_user_update ON users;
+DROP FUNCTION IF EXISTS count_follows_of_user();
+
+CREATE OR REPLACE FUNCTION count_follows() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE users SET following_count = following_count + 1 WHERE id = NEW.follower_id;
+        UPDATE users SET follower_count = follower_count + 1 WHERE id = NEW.followee_id;
+    ELSE
+        UPDATE users SET following_count = following_count - 1 WHERE id = OLD.follower_id;
+        UPDATE users SET follower_count = follower_count - 1 WHERE id = OLD.followee_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS follow_counted(INT, INT);
+
+UPDATE users SET
+    follower_count = (SELECT count(*) FROM follows WHERE followee_id = users.id),
+    following_count = (SELECT count(*) FROM follows WHERE follower_id = users.id);
@@ -0,0 +1,77 @@
+-- follower_count and following_count only count follows between two users
+-- that are not deleted, the ones GET /users/:id/followers and /following
+-- list. trg_count_follows skips the follows of deleted users and
+-- trg_count_follows_of_user moves the follows of a user in and out of the
+-- counts of the others when the user is deleted or restored.
+CREATE FUNCTION follow_counted(follower INT, followee INT) RETURNS boolean AS $$
+    SELECT count(*) = 2 FROM users WHERE id IN (follower, followee) AND deleted_at IS NULL;
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION count_follows() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        IF follow_counted(NEW.follower_id, NEW.followee_id) THEN
+            UPDATE users SET following_count = following_count + 1 WHERE id = NEW.follower_id;
+            UPDATE users SET follower_count = follower_count + 1 WHERE id = NEW.followee_id;
+        END IF;
+    ELSIF follow_counted(OLD.follower_id, OLD.followee_id) THEN
+        UPDATE users SET following_count = following_count - 1 WHERE id = OLD.follower_id;
+        UPDATE users SET follower_count = follower_count - 1 WHERE id = OLD.followee_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION count_follows_of_user() RETURNS trigger AS $$
+DECLARE
+    delta INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        -- drop the follows while the user is still there for
+        -- trg_count_follows to tell whether they were counted, rather than
+        -- leaving them to ON DELETE CASCADE
+        DELETE FROM follows WHERE follower_id = OLD.id OR followee_id = OLD.id;
+        RETURN OLD;
+    END IF;
+
+    IF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+        delta := -1;
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+        delta := 1;
+    ELSE
+        RETURN NEW;
+    END IF;
+
+    UPDATE users SET follower_count = follower_count + delta
+    WHERE deleted_at IS NULL AND id IN (SELECT followee_id FROM follows WHERE follower_id = OLD.id);
+    UPDATE users SET following_count = following_count + delta
+    WHERE deleted_at IS NULL AND id IN (SELECT follower_id FROM follows WHERE followee_id = OLD.id);
+
+    IF delta = 1 THEN
+        -- the counts of the user missed whoever was deleted or restored
+        -- while it was deleted itself
+        UPDATE users SET
+            follower_count = (SELECT count(*) FROM follows f JOIN users u ON u.id = f.follower_id
+                              WHERE f.followee_id = NEW.id AND u.deleted_at IS NULL),
+            following_count = (SELECT count(*) FROM follows f JOIN users u ON u.id = f.followee_id
+                               WHERE f.follower_id = NEW.id AND u.deleted_at IS NULL)
+        WHERE id = NEW.id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_count_follows_of_user_update
+    AFTER UPDATE OF deleted_at ON users
+    FOR EACH ROW EXECUTE FUNCTION count_follows_of_user();
+
+CREATE TRIGGER trg_count_follows_of_user_delete
+    BEFORE DELETE ON users
+    FOR EACH ROW EXECUTE FUNCTION count_follows_of_user();
+
+-- take the users deleted so far out of the counts
+UPDATE users SET
+    follower_count = (SELECT count(*) FROM follows f JOIN users u ON u.id = f.follower_id
+                      WHERE f.followee_id = users.id AND u.deleted_at IS NULL),
+    following_count = (SELECT count(*) FROM follows f JOIN users u ON u.id = f.followee_id
+                       WHERE f.follower_id = users.id AND u.deleted_at IS NULL);
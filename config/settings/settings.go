@@ -0,0 +1,577 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// default location of the optional config file, overridable with CONFIG_FILE
+const defaultConfigFile = "config.json"
+
+// Config holds every runtime setting of the application
+type Config struct {
+	Server   ServerConfig   `json:"server"`
+	Database DatabaseConfig `json:"database"`
+	JWT      JWTConfig      `json:"jwt"`
+	Comments CommentsConfig `json:"comments"`
+	Accounts AccountsConfig `json:"accounts"`
+	Content  ContentConfig  `json:"content"`
+	Outbound OutboundConfig `json:"outbound"`
+}
+
+// ServerConfig holds the HTTP server settings
+type ServerConfig struct {
+	Port string `json:"port"`
+}
+
+// DatabaseConfig holds the postgres connection and pool settings
+type DatabaseConfig struct {
+	URL             string   `json:"url"` // full connection string, takes precedence over the fields below
+	Host            string   `json:"host"`
+	Port            int      `json:"port"`
+	User            string   `json:"user"`
+	Password        string   `json:"password"`
+	Name            string   `json:"name"`
+	SSLMode         string   `json:"sslmode"`
+	MaxConns        int32    `json:"max_conns"`
+	MinConns        int32    `json:"min_conns"`
+	MaxConnLifetime Duration `json:"max_conn_lifetime"`
+	MaxConnIdleTime Duration `json:"max_conn_idle_time"`
+	ConnectTimeout  Duration `json:"connect_timeout"`
+}
+
+// JWTConfig holds the keys used to sign and verify access tokens and the
+// lifetime of access and refresh tokens
+type JWTConfig struct {
+	// ID of the key new tokens are signed with, defaults to the first active key
+	SigningKeyID string       `json:"signing_key_id"`
+	Keys         []SigningKey `json:"keys"`
+	TTL          Duration     `json:"ttl"`
+	RefreshTTL   Duration     `json:"refresh_ttl"`
+}
+
+// SigningKey is an HMAC secret identified by the `kid` token header.
+// Retired keys are kept only so tokens signed with them are rejected explicitly.
+type SigningKey struct {
+	ID      string `json:"id"`
+	Secret  string `json:"secret"`
+	Retired bool   `json:"retired"`
+}
+
+// CommentsConfig holds the rules of comment threads
+type CommentsConfig struct {
+	// how deep replies may be nested, top-level comments are at depth 0 and
+	// 0 disables replies altogether
+	MaxDepth int `json:"max_depth"`
+}
+
+// AccountsConfig holds the account lifecycle settings
+type AccountsConfig struct {
+	// how long a deleted account can still be restored by logging in before
+	// it is removed for good
+	DeletionGracePeriod Duration `json:"deletion_grace_period"`
+	// how often accounts past their grace period are looked for
+	PurgeInterval Duration `json:"purge_interval"`
+}
+
+// ContentConfig selects where the content of posts created without any comes from
+type ContentConfig struct {
+	// "api_ninjas", "file" or "static"; api_ninjas falls back to the file and
+	// the file to the static text when they fail
+	Provider  string          `json:"provider"`
+	APINinjas APINinjasConfig `json:"api_ninjas"`
+	// jokes and quotes, one per line, defaults to the file bundled in the binary
+	File       string `json:"file"`
+	StaticText string `json:"static_text"`
+}
+
+// APINinjasConfig holds the settings of the api-ninjas jokes API
+type APINinjasConfig struct {
+	URL string `json:"url"`
+	Key string `json:"key"`
+	// Timeout bounds each attempt, TotalTimeout the whole call with its
+	// retries, after which the post falls back to the jokes file
+	Timeout      Duration `json:"timeout"`
+	TotalTimeout Duration `json:"total_timeout"`
+}
+
+// OutboundConfig holds how calls to third-party APIs are retried and when
+// an API that keeps failing stops being called for a while
+type OutboundConfig struct {
+	// retries after the first attempt of a call failing with 429, 5xx or a
+	// network error, 0 disables retries
+	MaxRetries int `json:"max_retries"`
+	// the wait before the first retry, doubled on every retry up to
+	// MaxRetryBackoff and jittered
+	RetryBackoff    Duration `json:"retry_backoff"`
+	MaxRetryBackoff Duration `json:"max_retry_backoff"`
+	// consecutive failed calls that open the circuit breaker, which then
+	// fails calls right away for BreakerCooldown before letting one through
+	BreakerThreshold int      `json:"breaker_threshold"`
+	BreakerCooldown  Duration `json:"breaker_cooldown"`
+}
+
+// content providers accepted by ContentConfig.Provider
+const (
+	ContentAPINinjas = "api_ninjas"
+	ContentFile      = "file"
+	ContentStatic    = "static"
+)
+
+// minimum HMAC secret length, matching the SHA-256 output size
+const minSecretLength = 32
+
+// Duration is a time.Duration that is written as "5s", "1m30s", ... in the config file
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"5s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Default returns the configuration used when nothing else is provided,
+// suitable for a local postgres
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port: "8080",
+		},
+		Database: DatabaseConfig{
+			Host:            "localhost",
+			Port:            5432,
+			User:            "postgres",
+			Name:            "postgres",
+			SSLMode:         "prefer",
+			MaxConns:        10,
+			MinConns:        0,
+			MaxConnLifetime: Duration(time.Hour),
+			MaxConnIdleTime: Duration(30 * time.Minute),
+			ConnectTimeout:  Duration(5 * time.Second),
+		},
+		JWT: JWTConfig{
+			TTL:        Duration(15 * time.Minute),
+			RefreshTTL: Duration(30 * 24 * time.Hour),
+		},
+		Comments: CommentsConfig{
+			MaxDepth: 3,
+		},
+		Accounts: AccountsConfig{
+			DeletionGracePeriod: Duration(30 * 24 * time.Hour),
+			PurgeInterval:       Duration(time.Hour),
+		},
+		Content: ContentConfig{
+			Provider: ContentAPINinjas,
+			APINinjas: APINinjasConfig{
+				URL:          "https://api.api-ninjas.com/v1/jokes",
+				Timeout:      Duration(3 * time.Second),
+				TotalTimeout: Duration(5 * time.Second),
+			},
+			StaticText: "Nothing to say today, but glad to be here.",
+		},
+		Outbound: OutboundConfig{
+			MaxRetries:       2,
+			RetryBackoff:     Duration(100 * time.Millisecond),
+			MaxRetryBackoff:  Duration(time.Second),
+			BreakerThreshold: 5,
+			BreakerCooldown:  Duration(30 * time.Second),
+		},
+	}
+}
+
+// Load builds the configuration from, in increasing order of precedence:
+// the defaults, the optional JSON config file and the environment.
+// A .env file is loaded into the environment when present but is not required.
+func Load() (*Config, error) {
+	// .env is a convenience for local development, never mandatory
+	if err := godotenv.Load(envOr("ENV_FILE", ".env")); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load env file: %w", err)
+	}
+
+	cfg := Default()
+
+	// the config file is only required when explicitly requested
+	path, explicit := os.LookupEnv("CONFIG_FILE")
+	if !explicit {
+		path = defaultConfigFile
+	}
+	if err := loadFile(path, &cfg); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) || explicit {
+			return nil, err
+		}
+	}
+
+	if err := loadEnv(&cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate reports settings that cannot possibly work. The JWT settings are
+// left to token.NewService since only the server signs tokens, so migrate
+// and set-role run without a signing key.
+func (c *Config) Validate() error {
+	db := c.Database
+	if db.URL == "" {
+		if db.Host == "" {
+			return errors.New("database host must not be empty")
+		}
+		if db.Port <= 0 || db.Port > 65535 {
+			return fmt.Errorf("database port %d is out of range", db.Port)
+		}
+		if db.User == "" {
+			return errors.New("database user must not be empty")
+		}
+		if db.Name == "" {
+			return errors.New("database name must not be empty")
+		}
+	}
+	if db.MaxConns <= 0 {
+		return errors.New("database max_conns must be greater than 0")
+	}
+	if db.MinConns < 0 || db.MinConns > db.MaxConns {
+		return fmt.Errorf("database min_conns must be between 0 and max_conns (%d)", db.MaxConns)
+	}
+	if c.Server.Port == "" {
+		return errors.New("server port must not be empty")
+	}
+	if c.Comments.MaxDepth < 0 {
+		return errors.New("comments max_depth must not be negative")
+	}
+	if c.Accounts.DeletionGracePeriod < 0 {
+		return errors.New("accounts deletion_grace_period must not be negative")
+	}
+	if c.Accounts.PurgeInterval <= 0 {
+		return errors.New("accounts purge_interval must be greater than 0")
+	}
+	if err := c.Content.Validate(); err != nil {
+		return err
+	}
+	return c.Outbound.Validate()
+}
+
+// Validate checks that there is a usable signing key
+func (j *JWTConfig) Validate() error {
+	if len(j.Keys) == 0 {
+		return errors.New("no JWT signing key configured, set JWT_SECRET or JWT_KEYS")
+	}
+
+	seen := map[string]bool{}
+	for _, key := range j.Keys {
+		if key.ID == "" {
+			return errors.New("every JWT key needs an id")
+		}
+		if seen[key.ID] {
+			return fmt.Errorf("JWT key id %q is used twice", key.ID)
+		}
+		seen[key.ID] = true
+		if !key.Retired && len(key.Secret) < minSecretLength {
+			return fmt.Errorf("JWT key %q must be at least %d characters long", key.ID, minSecretLength)
+		}
+	}
+
+	signing, ok := j.SigningKey()
+	if !ok {
+		return fmt.Errorf("JWT signing key %q is not configured", j.SigningKeyID)
+	}
+	if signing.Retired {
+		return fmt.Errorf("JWT signing key %q is retired", signing.ID)
+	}
+	if j.TTL <= 0 {
+		return errors.New("JWT ttl must be greater than 0")
+	}
+	if j.RefreshTTL <= j.TTL {
+		return errors.New("JWT refresh_ttl must be longer than ttl")
+	}
+	return nil
+}
+
+// Validate checks that the selected content provider can be built
+func (c *ContentConfig) Validate() error {
+	switch c.Provider {
+	case ContentAPINinjas:
+		if _, err := url.ParseRequestURI(c.APINinjas.URL); err != nil {
+			return fmt.Errorf("content api_ninjas url is invalid: %w", err)
+		}
+		if c.APINinjas.Timeout <= 0 {
+			return errors.New("content api_ninjas timeout must be greater than 0")
+		}
+		if c.APINinjas.TotalTimeout < c.APINinjas.Timeout {
+			return errors.New("content api_ninjas total_timeout must not be shorter than timeout")
+		}
+	case ContentFile, ContentStatic:
+	default:
+		return fmt.Errorf("content provider must be %q, %q or %q, not %q", ContentAPINinjas, ContentFile, ContentStatic, c.Provider)
+	}
+	if strings.TrimSpace(c.StaticText) == "" {
+		return errors.New("content static_text must not be empty")
+	}
+	return nil
+}
+
+// Validate checks the retry and circuit breaker settings
+func (o *OutboundConfig) Validate() error {
+	if o.MaxRetries < 0 {
+		return errors.New("outbound max_retries must not be negative")
+	}
+	if o.RetryBackoff <= 0 {
+		return errors.New("outbound retry_backoff must be greater than 0")
+	}
+	if o.MaxRetryBackoff < o.RetryBackoff {
+		return errors.New("outbound max_retry_backoff must not be shorter than retry_backoff")
+	}
+	if o.BreakerThreshold <= 0 {
+		return errors.New("outbound breaker_threshold must be greater than 0")
+	}
+	if o.BreakerCooldown <= 0 {
+		return errors.New("outbound breaker_cooldown must be greater than 0")
+	}
+	return nil
+}
+
+// SigningKey returns the key new tokens are signed with
+func (j *JWTConfig) SigningKey() (SigningKey, bool) {
+	for _, key := range j.Keys {
+		if j.SigningKeyID == "" && !key.Retired {
+			return key, true
+		}
+		if j.SigningKeyID != "" && key.ID == j.SigningKeyID {
+			return key, true
+		}
+	}
+	return SigningKey{}, false
+}
+
+// DSN returns the postgres connection string for the database settings
+func (d DatabaseConfig) DSN() string {
+	if d.URL != "" {
+		return d.URL
+	}
+
+	u := url.URL{
+		Scheme: "postgresql",
+		User:   url.UserPassword(d.User, d.Password),
+		Host:   fmt.Sprintf("%s:%d", d.Host, d.Port),
+		Path:   "/" + d.Name,
+	}
+	if d.Password == "" {
+		u.User = url.User(d.User)
+	}
+
+	query := url.Values{}
+	if d.SSLMode != "" {
+		query.Set("sslmode", d.SSLMode)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// read the JSON config file at path into cfg
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// override cfg with whichever environment variables are set
+func loadEnv(cfg *Config) error {
+	db := &cfg.Database
+
+	setString(&cfg.Server.Port, "PORT")
+
+	// DATABASE_URL is what most hosting platforms inject, DB_URL is accepted
+	// too; rather than pick one, two different URLs are rejected
+	if a, b := os.Getenv("DATABASE_URL"), os.Getenv("DB_URL"); a != "" && b != "" && a != b {
+		return errors.New("DATABASE_URL and DB_URL are set to different URLs, set only one of them")
+	}
+	setString(&db.URL, "DATABASE_URL")
+	setString(&db.URL, "DB_URL")
+	setString(&db.Host, "DB_HOST")
+	setString(&db.User, "DB_USER")
+	setString(&db.Password, "DB_PASSWORD")
+	setString(&db.Name, "DB_NAME")
+	setString(&db.SSLMode, "DB_SSLMODE")
+
+	if err := setInt(&db.Port, "DB_PORT"); err != nil {
+		return err
+	}
+	if err := setInt32(&db.MaxConns, "DB_MAX_CONNS"); err != nil {
+		return err
+	}
+	if err := setInt32(&db.MinConns, "DB_MIN_CONNS"); err != nil {
+		return err
+	}
+	if err := setDuration(&db.MaxConnLifetime, "DB_MAX_CONN_LIFETIME"); err != nil {
+		return err
+	}
+	if err := setDuration(&db.MaxConnIdleTime, "DB_MAX_CONN_IDLE_TIME"); err != nil {
+		return err
+	}
+	if err := setDuration(&db.ConnectTimeout, "DB_CONNECT_TIMEOUT"); err != nil {
+		return err
+	}
+
+	if err := setInt(&cfg.Comments.MaxDepth, "COMMENT_MAX_DEPTH"); err != nil {
+		return err
+	}
+	if err := setDuration(&cfg.Accounts.DeletionGracePeriod, "ACCOUNT_DELETION_GRACE_PERIOD"); err != nil {
+		return err
+	}
+	if err := setDuration(&cfg.Accounts.PurgeInterval, "ACCOUNT_PURGE_INTERVAL"); err != nil {
+		return err
+	}
+
+	setString(&cfg.Content.Provider, "CONTENT_PROVIDER")
+	setString(&cfg.Content.File, "CONTENT_FILE")
+	setString(&cfg.Content.StaticText, "CONTENT_STATIC_TEXT")
+	setString(&cfg.Content.APINinjas.URL, "API_NINJAS_URL")
+	setString(&cfg.Content.APINinjas.Key, "API_NINJAS_KEY")
+	if err := setDuration(&cfg.Content.APINinjas.Timeout, "API_NINJAS_TIMEOUT"); err != nil {
+		return err
+	}
+	if err := setDuration(&cfg.Content.APINinjas.TotalTimeout, "API_NINJAS_TOTAL_TIMEOUT"); err != nil {
+		return err
+	}
+
+	out := &cfg.Outbound
+	if err := setInt(&out.MaxRetries, "OUTBOUND_MAX_RETRIES"); err != nil {
+		return err
+	}
+	if err := setDuration(&out.RetryBackoff, "OUTBOUND_RETRY_BACKOFF"); err != nil {
+		return err
+	}
+	if err := setDuration(&out.MaxRetryBackoff, "OUTBOUND_MAX_RETRY_BACKOFF"); err != nil {
+		return err
+	}
+	if err := setInt(&out.BreakerThreshold, "OUTBOUND_BREAKER_THRESHOLD"); err != nil {
+		return err
+	}
+	if err := setDuration(&out.BreakerCooldown, "OUTBOUND_BREAKER_COOLDOWN"); err != nil {
+		return err
+	}
+
+	return loadJWTEnv(&cfg.JWT)
+}
+
+// JWT_SECRET configures a single key, JWT_KEYS="kid:secret,kid:secret" several
+// for rotation; JWT_RETIRED_KEYS="kid,kid" lists the ids that must be rejected
+func loadJWTEnv(j *JWTConfig) error {
+	if secret, ok := os.LookupEnv("JWT_SECRET"); ok && secret != "" {
+		j.Keys = []SigningKey{{ID: "default", Secret: secret}}
+	}
+
+	if v, ok := os.LookupEnv("JWT_KEYS"); ok && v != "" {
+		j.Keys = nil
+		for _, pair := range strings.Split(v, ",") {
+			id, secret, found := strings.Cut(strings.TrimSpace(pair), ":")
+			if !found {
+				return errors.New(`JWT_KEYS must look like "kid:secret,kid:secret"`)
+			}
+			j.Keys = append(j.Keys, SigningKey{ID: id, Secret: secret})
+		}
+	}
+
+	if v, ok := os.LookupEnv("JWT_RETIRED_KEYS"); ok && v != "" {
+		for _, id := range strings.Split(v, ",") {
+			id = strings.TrimSpace(id)
+			found := false
+			for i := range j.Keys {
+				if j.Keys[i].ID == id {
+					j.Keys[i].Retired = true
+					found = true
+				}
+			}
+			if !found {
+				j.Keys = append(j.Keys, SigningKey{ID: id, Retired: true})
+			}
+		}
+	}
+
+	setString(&j.SigningKeyID, "JWT_SIGNING_KEY_ID")
+	if err := setDuration(&j.TTL, "JWT_TTL"); err != nil {
+		return err
+	}
+	return setDuration(&j.RefreshTTL, "JWT_REFRESH_TTL")
+}
+
+func envOr(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return v
+	}
+	return fallback
+}
+
+func setString(dst *string, key string) {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		*dst = v
+	}
+}
+
+func setInt(dst *int, key string) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%s must be an integer: %w", key, err)
+	}
+	*dst = n
+	return nil
+}
+
+func setInt32(dst *int32, key string) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	n, err := strconv.ParseInt(v, 10, 32)
+	if err != nil {
+		return fmt.Errorf("%s must be an integer: %w", key, err)
+	}
+	*dst = int32(n)
+	return nil
+}
+
+func setDuration(dst *Duration, key string) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%s must be a duration like \"5s\": %w", key, err)
+	}
+	*dst = Duration(d)
+	return nil
+}
@@ -0,0 +1,58 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadEnvDatabaseURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		databaseURL string
+		dbURL       string
+		want        string
+		wantErr     bool
+	}{
+		{"DATABASE_URL", "postgres://a/db", "", "postgres://a/db", false},
+		{"DB_URL", "", "postgres://b/db", "postgres://b/db", false},
+		{"both the same", "postgres://a/db", "postgres://a/db", "postgres://a/db", false},
+		{"both different", "postgres://a/db", "postgres://b/db", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DATABASE_URL", tt.databaseURL)
+			t.Setenv("DB_URL", tt.dbURL)
+			cfg := Default()
+			err := loadEnv(&cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadEnv: %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.Database.URL != tt.want {
+				t.Errorf("URL %q, want %q", cfg.Database.URL, tt.want)
+			}
+		})
+	}
+}
+
+// migrate and set-role load the configuration without any JWT key
+func TestLoadWithoutJWTKeys(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ENV_FILE", filepath.Join(dir, ".env"))
+	t.Setenv("CONFIG_FILE", filepath.Join(dir, "config.json"))
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("JWT_KEYS", "")
+	t.Setenv("DATABASE_URL", "postgres://a/db")
+	t.Setenv("DB_URL", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := cfg.JWT.Validate(); err == nil {
+		t.Error("JWT.Validate without keys succeeded")
+	}
+}
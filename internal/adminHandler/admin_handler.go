@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"w3/gc3/internal/apperror"
+	"w3/gc3/internal/auth"
+	"w3/gc3/internal/repository"
+)
+
+// UpdateRoleRequest struct
+type UpdateRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user moderator admin"`
+}
+
+// AdminHandler serves the /admin endpoints, every route is behind auth.Require(auth.ManageUsers)
+type AdminHandler struct {
+	users repository.UserRepository
+	uow   repository.UnitOfWork
+}
+
+func NewAdminHandler(users repository.UserRepository, uow repository.UnitOfWork) *AdminHandler {
+	return &AdminHandler{users: users, uow: uow}
+}
+
+// @Summary List users
+// @Description Retrieve every user account (admin only)
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {array} model.User "List of users"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /admin/users [get]
+func (h *AdminHandler) ListUsers(c echo.Context) error {
+	users, err := h.users.List(c.Request().Context())
+	if err != nil {
+		return apperror.Internal(err, "failed to fetch users")
+	}
+	return c.JSON(http.StatusOK, users)
+}
+
+// @Summary Change the role of a user
+// @Description Grant or revoke the moderator and admin roles (admin only). The user's tokens are revoked so the new role applies at their next login.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Param request body UpdateRoleRequest true "New role"
+// @Success 200 {object} map[string]string "Role updated successfully"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "User not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /admin/users/{id}/role [patch]
+func (h *AdminHandler) UpdateRole(c echo.Context) error {
+	principal, _ := auth.CurrentUser(c)
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("invalid user ID")
+	}
+
+	var req UpdateRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.BadRequest("invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	// an admin demoting themselves could leave nobody able to manage users
+	if userID == principal.UserID {
+		return apperror.BadRequest("you cannot change your own role")
+	}
+
+	ctx := c.Request().Context()
+	err = h.uow.Do(ctx, func(repos repository.Repositories) error {
+		if err := repos.Users.UpdateRole(ctx, userID, req.Role); err != nil {
+			return err
+		}
+		if err := repos.Sessions.RevokeAll(ctx, userID); err != nil {
+			return err
+		}
+		if err := repos.RefreshTokens.RevokeAllForUser(ctx, userID); err != nil {
+			return err
+		}
+		description := fmt.Sprintf("Admin changed the role of USER with ID %d to %s", userID, req.Role)
+		return repos.Activities.Log(ctx, principal.UserID, description)
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound("user not found")
+	}
+	if err != nil {
+		return apperror.Internal(err, "failed to update role")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "role updated successfully"})
+}
+
+// @Summary Delete a user
+// @Description Remove a user account together with its posts, comments and logs (admin only)
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]string "User deleted successfully"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "User not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /admin/users/{id} [delete]
+func (h *AdminHandler) DeleteUser(c echo.Context) error {
+	principal, _ := auth.CurrentUser(c)
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("invalid user ID")
+	}
+	if userID == principal.UserID {
+		return apperror.BadRequest("you cannot delete your own account here")
+	}
+
+	ctx := c.Request().Context()
+	err = h.uow.Do(ctx, func(repos repository.Repositories) error {
+		if err := repos.Users.Delete(ctx, userID); err != nil {
+			return err
+		}
+		description := "Admin deleted USER with ID " + strconv.Itoa(userID)
+		return repos.Activities.Log(ctx, principal.UserID, description)
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound("user not found")
+	}
+	if err != nil {
+		return apperror.Internal(err, "failed to delete user")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "user deleted successfully"})
+}
@@ -0,0 +1,109 @@
+package apperror
+
+import (
+	"net/http"
+
+	"w3/gc3/internal/validation"
+)
+
+// Code is the machine-readable kind of an error, sent to clients
+type Code string
+
+const (
+	CodeBadRequest   Code = "bad_request"
+	CodeValidation   Code = "validation_failed"
+	CodeUnauthorized Code = "unauthorized"
+	CodeForbidden    Code = "forbidden"
+	CodeNotFound     Code = "not_found"
+	CodeConflict     Code = "conflict"
+	CodeInternal     Code = "internal"
+)
+
+// Error is an error handlers return to fail a request, HTTPErrorHandler turns
+// it into the response
+type Error struct {
+	Code    Code
+	Message string            // safe to show to clients
+	Fields  validation.Errors // the invalid fields, for CodeValidation
+	Err     error             // the cause, logged but never sent
+
+	status int // overrides the status of Code, see From
+}
+
+// Kinds to match errors against with errors.Is, e.g. errors.Is(err, apperror.ErrNotFound)
+var (
+	ErrBadRequest   = &Error{Code: CodeBadRequest}
+	ErrValidation   = &Error{Code: CodeValidation}
+	ErrUnauthorized = &Error{Code: CodeUnauthorized}
+	ErrForbidden    = &Error{Code: CodeForbidden}
+	ErrNotFound     = &Error{Code: CodeNotFound}
+	ErrConflict     = &Error{Code: CodeConflict}
+	ErrInternal     = &Error{Code: CodeInternal}
+)
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the kind of e, see ErrNotFound and friends
+func (e *Error) Is(target error) bool {
+	kind, ok := target.(*Error)
+	return ok && kind.Message == "" && kind.Err == nil && kind.Code == e.Code
+}
+
+// Status is the HTTP status code of the response
+func (e *Error) Status() int {
+	if e.status != 0 {
+		return e.status
+	}
+	switch e.Code {
+	case CodeBadRequest, CodeValidation:
+		return http.StatusBadRequest
+	case CodeUnauthorized:
+		return http.StatusUnauthorized
+	case CodeForbidden:
+		return http.StatusForbidden
+	case CodeNotFound:
+		return http.StatusNotFound
+	case CodeConflict:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func BadRequest(message string) *Error {
+	return &Error{Code: CodeBadRequest, Message: message}
+}
+
+// Validation reports the invalid fields of a request
+func Validation(fields validation.Errors) *Error {
+	return &Error{Code: CodeValidation, Message: "validation failed", Fields: fields}
+}
+
+func Unauthorized(message string) *Error {
+	return &Error{Code: CodeUnauthorized, Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Code: CodeForbidden, Message: message}
+}
+
+func NotFound(message string) *Error {
+	return &Error{Code: CodeNotFound, Message: message}
+}
+
+func Conflict(message string) *Error {
+	return &Error{Code: CodeConflict, Message: message}
+}
+
+// Internal hides err from the client behind message
+func Internal(err error, message string) *Error {
+	return &Error{Code: CodeInternal, Message: message, Err: err}
+}
@@ -0,0 +1,163 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"w3/gc3/internal/repository"
+	"w3/gc3/internal/validation"
+)
+
+// MIMEProblemJSON is the media type of RFC 7807 problem details
+const MIMEProblemJSON = "application/problem+json"
+
+// Response is the body of every failed request
+type Response struct {
+	Error Body `json:"error"`
+}
+
+// Body describes what went wrong
+type Body struct {
+	Code      Code              `json:"code"`
+	Message   string            `json:"message"`
+	Fields    validation.Errors `json:"fields,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+// Problem is the RFC 7807 form of Response, sent to clients that accept
+// application/problem+json
+type Problem struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail"`
+	Instance  string            `json:"instance"`
+	Code      Code              `json:"code"`
+	Fields    validation.Errors `json:"errors,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+// HTTPErrorHandler is the echo.HTTPErrorHandler of the application: it renders
+// every error returned by a handler or middleware as a Response, or as a
+// Problem when the client asks for one, and logs internal errors
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	appErr := From(err)
+	status := appErr.Status()
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	if status >= http.StatusInternalServerError {
+		c.Logger().Errorf("request %s: %v", requestID, err)
+	}
+
+	var sendErr error
+	switch {
+	case c.Request().Method == http.MethodHead:
+		sendErr = c.NoContent(status)
+	case strings.Contains(c.Request().Header.Get(echo.HeaderAccept), MIMEProblemJSON):
+		c.Response().Header().Set(echo.HeaderContentType, MIMEProblemJSON)
+		sendErr = c.JSON(status, Problem{
+			Type:      "about:blank",
+			Title:     http.StatusText(status),
+			Status:    status,
+			Detail:    appErr.Message,
+			Instance:  c.Request().URL.Path,
+			Code:      appErr.Code,
+			Fields:    appErr.Fields,
+			RequestID: requestID,
+		})
+	default:
+		sendErr = c.JSON(status, Response{Error: Body{
+			Code:      appErr.Code,
+			Message:   appErr.Message,
+			Fields:    appErr.Fields,
+			RequestID: requestID,
+		}})
+	}
+	if sendErr != nil {
+		c.Logger().Error(sendErr)
+	}
+}
+
+// From turns any error into an *Error: validation errors become CodeValidation,
+// storage errors the handler left unhandled, even behind Internal, get their
+// kind from storage, echo's own client errors (unknown routes, bad bodies, ...)
+// keep their status and anything else is internal
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		if appErr.Code == CodeInternal {
+			if storageErr := storage(appErr.Err); storageErr != nil {
+				return storageErr
+			}
+		}
+		return appErr
+	}
+
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		return Validation(fields)
+	}
+
+	if storageErr := storage(err); storageErr != nil {
+		return storageErr
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) && httpErr.Code < http.StatusInternalServerError {
+		return &Error{
+			Code:    codeOf(httpErr.Code),
+			Message: strings.ToLower(fmt.Sprint(httpErr.Message)),
+			Err:     err,
+			status:  httpErr.Code,
+		}
+	}
+
+	return Internal(err, "internal server error")
+}
+
+// codeOf returns the Code of a client error status: the Code used for it by
+// Error.Status, or else its status text in snake case ("method_not_allowed")
+func codeOf(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	}
+	return Code(strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_"))
+}
+
+// storage maps the errors of the repository package onto their kind, keeping
+// err as the cause: missing records and references are CodeNotFound,
+// uniqueness violations CodeConflict and other violated rules CodeBadRequest.
+// It returns nil for any other error
+func storage(err error) *Error {
+	var appErr *Error
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		appErr = NotFound("record not found")
+	case errors.Is(err, repository.ErrInvalidReference):
+		appErr = NotFound("referenced record does not exist")
+	case errors.Is(err, repository.ErrDuplicate):
+		appErr = Conflict("record already exists")
+	case errors.Is(err, repository.ErrConstraint):
+		appErr = BadRequest("request violates a constraint")
+	default:
+		return nil
+	}
+	appErr.Err = err
+	return appErr
+}
@@ -0,0 +1,100 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"w3/gc3/internal/repository"
+	"w3/gc3/internal/validation"
+)
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   Code
+	}{
+		{"app error", NotFound("post not found"), http.StatusNotFound, CodeNotFound},
+		{"wrapped app error", fmt.Errorf("wrapped: %w", Forbidden("no")), http.StatusForbidden, CodeForbidden},
+		{"validation errors", validation.Errors{{Field: "email", Code: "required"}}, http.StatusBadRequest, CodeValidation},
+		{"echo client error", echo.ErrMethodNotAllowed, http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"echo not found", echo.ErrNotFound, http.StatusNotFound, CodeNotFound},
+		{"echo server error", echo.ErrInternalServerError, http.StatusInternalServerError, CodeInternal},
+		{"internal", Internal(errors.New("boom"), "failed"), http.StatusInternalServerError, CodeInternal},
+		{"not found", repository.ErrNotFound, http.StatusNotFound, CodeNotFound},
+		{"duplicate", fmt.Errorf("%w: users_email_key", repository.ErrDuplicate), http.StatusConflict, CodeConflict},
+		{"invalid reference", fmt.Errorf("%w: fk_post_comments", repository.ErrInvalidReference), http.StatusNotFound, CodeNotFound},
+		{"constraint", fmt.Errorf("%w: chk_follows_self", repository.ErrConstraint), http.StatusBadRequest, CodeBadRequest},
+		// handlers wrapping storage errors they did not expect still get their kind
+		{"storage error behind internal", Internal(repository.ErrInvalidReference, "failed"), http.StatusNotFound, CodeNotFound},
+		// but what handlers made of them themselves stands
+		{"storage error behind conflict", &Error{Code: CodeConflict, Message: "taken", Err: repository.ErrNotFound}, http.StatusConflict, CodeConflict},
+		{"anything else", errors.New("boom"), http.StatusInternalServerError, CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := From(tt.err)
+			if got.Status() != tt.status || got.Code != tt.code {
+				t.Errorf("From(%v) = %d %q, want %d %q", tt.err, got.Status(), got.Code, tt.status, tt.code)
+			}
+		})
+	}
+
+	if msg := From(errors.New("secret detail")).Message; msg != "internal server error" {
+		t.Errorf("internal message %q leaks the cause", msg)
+	}
+}
+
+func TestIs(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", NotFound("post not found"))
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("a NotFound error is not ErrNotFound")
+	}
+	if errors.Is(err, ErrConflict) {
+		t.Error("a NotFound error is ErrConflict")
+	}
+}
+
+func serve(err error, accept string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/posts/1", nil)
+	req.Header.Set(echo.HeaderAccept, accept)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Response().Header().Set(echo.HeaderXRequestID, "req-1")
+	HTTPErrorHandler(err, c)
+	return rec
+}
+
+func TestHTTPErrorHandler(t *testing.T) {
+	rec := serve(Validation(validation.Errors{{Field: "email", Code: "required", Message: "is required"}}), echo.MIMEApplicationJSON)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400", rec.Code)
+	}
+	var resp Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error.Code != CodeValidation || resp.Error.RequestID != "req-1" || len(resp.Error.Fields) != 1 {
+		t.Errorf("body %+v", resp)
+	}
+
+	rec = serve(NotFound("post not found"), MIMEProblemJSON)
+	if ct := rec.Header().Get(echo.HeaderContentType); ct != MIMEProblemJSON {
+		t.Errorf("content type %q, want %s", ct, MIMEProblemJSON)
+	}
+	var problem Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Status != http.StatusNotFound || problem.Detail != "post not found" || problem.Instance != "/posts/1" || problem.Code != CodeNotFound {
+		t.Errorf("problem %+v", problem)
+	}
+}
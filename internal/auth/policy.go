@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"w3/gc3/internal/apperror"
+)
+
+// Roles stored on users.role, each one includes the permissions of the previous
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+var roleRank = map[string]int{
+	RoleUser:      1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+}
+
+// ValidRole reports whether role is one of the known roles
+func ValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// RolesFor expands the stored role into every role it implies, as embedded in tokens
+func RolesFor(role string) []string {
+	roles := []string{}
+	for _, r := range []string{RoleUser, RoleModerator, RoleAdmin} {
+		if roleRank[r] <= roleRank[role] {
+			roles = append(roles, r)
+		}
+	}
+	return roles
+}
+
+// Action is something a principal may or may not be allowed to do
+type Action string
+
+const (
+	EditPost      Action = "post:edit"
+	DeletePost    Action = "post:delete"
+	EditComment   Action = "comment:edit"
+	DeleteComment Action = "comment:delete"
+	ManageUsers   Action = "users:manage"
+	ViewMetrics   Action = "metrics:view"
+)
+
+// a rule decides for a resource owned by ownerID (0 when not applicable)
+type rule func(p *Principal, ownerID int) bool
+
+func owner(p *Principal, ownerID int) bool {
+	return ownerID != 0 && p.UserID == ownerID
+}
+
+func role(name string) rule {
+	return func(p *Principal, _ int) bool {
+		return p.HasRole(name)
+	}
+}
+
+func anyOf(rules ...rule) rule {
+	return func(p *Principal, ownerID int) bool {
+		for _, r := range rules {
+			if r(p, ownerID) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// policies is the single place where permissions are decided
+var policies = map[Action]rule{
+	EditPost:      owner,
+	DeletePost:    anyOf(owner, role(RoleModerator)),
+	EditComment:   anyOf(owner, role(RoleModerator)),
+	DeleteComment: anyOf(owner, role(RoleModerator)),
+	ManageUsers:   role(RoleAdmin),
+	ViewMetrics:   role(RoleAdmin),
+}
+
+// Can reports whether p may perform action on a resource owned by ownerID
+func Can(p *Principal, action Action, ownerID int) bool {
+	allowed, ok := policies[action]
+	return ok && p != nil && allowed(p, ownerID)
+}
+
+// Require rejects requests whose principal may not perform action, for
+// actions that do not depend on a resource owner
+func Require(action Action) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := CurrentUser(c)
+			if !ok {
+				return apperror.Unauthorized("not authorized")
+			}
+			if !Can(principal, action, 0) {
+				return apperror.Forbidden("you are not allowed to perform this action")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package auth
+
+import "testing"
+
+func TestRolesFor(t *testing.T) {
+	got := RolesFor(RoleModerator)
+	if len(got) != 2 || got[0] != RoleUser || got[1] != RoleModerator {
+		t.Errorf("RolesFor(moderator) = %v, want [user moderator]", got)
+	}
+	if got := RolesFor("unknown"); len(got) != 0 {
+		t.Errorf("RolesFor(unknown) = %v, want none", got)
+	}
+}
+
+func TestCan(t *testing.T) {
+	const ownerID = 1
+	user := &Principal{UserID: 2, Roles: RolesFor(RoleUser)}
+	owner := &Principal{UserID: ownerID, Roles: RolesFor(RoleUser)}
+	moderator := &Principal{UserID: 3, Roles: RolesFor(RoleModerator)}
+	admin := &Principal{UserID: 4, Roles: RolesFor(RoleAdmin)}
+
+	tests := []struct {
+		action    Action
+		principal *Principal
+		owner     int
+		want      bool
+	}{
+		{EditPost, owner, ownerID, true},
+		{EditPost, user, ownerID, false},
+		{EditPost, moderator, ownerID, false}, // moderators remove posts, they do not rewrite them
+		{EditPost, admin, ownerID, false},
+		{DeletePost, owner, ownerID, true},
+		{DeletePost, user, ownerID, false},
+		{DeletePost, moderator, ownerID, true},
+		{DeletePost, admin, ownerID, true},
+		{EditComment, user, ownerID, false},
+		{EditComment, moderator, ownerID, true},
+		{DeleteComment, owner, ownerID, true},
+		{DeleteComment, user, ownerID, false},
+		{ManageUsers, moderator, 0, false},
+		{ManageUsers, admin, 0, true},
+		{ViewMetrics, moderator, 0, false},
+		{ViewMetrics, admin, 0, true},
+		// no owner never matches the owner rule, even for user 0
+		{DeletePost, &Principal{Roles: RolesFor(RoleUser)}, 0, false},
+		{DeletePost, nil, ownerID, false},
+		{Action("unknown"), admin, 0, false},
+	}
+	for _, tt := range tests {
+		if got := Can(tt.principal, tt.action, tt.owner); got != tt.want {
+			t.Errorf("Can(%+v, %s, %d) = %v, want %v", tt.principal, tt.action, tt.owner, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// context key the JWT middleware stores the principal under
+const principalKey = "principal"
+
+// Principal is the authenticated user of a request, as stated by its access token
+type Principal struct {
+	UserID    int
+	Username  string
+	Roles     []string
+	TokenID   string    // jti of the access token
+	SessionID string    // refresh token family the access token belongs to
+	ExpiresAt time.Time // expiry of the access token
+}
+
+// HasRole reports whether the principal was granted role
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// SetPrincipal attaches the authenticated user to the request context
+func SetPrincipal(c echo.Context, p *Principal) {
+	c.Set(principalKey, p)
+}
+
+// CurrentUser returns the authenticated user of the request, ok is false
+// when the route is not behind the JWT middleware
+func CurrentUser(c echo.Context) (p *Principal, ok bool) {
+	p, ok = c.Get(principalKey).(*Principal)
+	return p, ok && p != nil
+}
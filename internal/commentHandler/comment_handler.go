@@ -1,74 +1,126 @@
 package handler
 
 import (
-	"database/sql"
-	"fmt"
+	"errors"
 	"net/http"
 	"strconv"
 
-	config "w3/gc3/config/database"
-	activity_handler "w3/gc3/internal/activityHandler"
-	"w3/gc3/utils"
+	"w3/gc3/internal/apperror"
+	"w3/gc3/internal/auth"
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/pagination"
+	"w3/gc3/internal/repository"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
 
-// Comment struct based on the provided schema
-type Comment struct {
-	ID      int    `json:"id"`
-	Content string `json:"content" validate:"required"`
-	PostID  int    `json:"post_id" validate:"required"`
-	AuthorID int   `json:"author_id"`
+// UpdateCommentRequest struct
+type UpdateCommentRequest struct {
+	Content string `json:"content" validate:"required,notblank"`
 }
 
-// Validator instance
-var validate = validator.New()
+// CommentListResponse is one page of the replies to a comment
+type CommentListResponse struct {
+	Comments   []model.PostComment `json:"comments"`
+	NextCursor string              `json:"next_cursor"` // empty on the last page
+}
+
+// errors returned from inside a unit of work to pick the response
+var (
+	errForbidden      = errors.New("forbidden")
+	errParentNotFound = errors.New("parent comment not found")
+	errParentMismatch = errors.New("parent comment belongs to another post")
+	errTooDeep        = errors.New("replies nested too deep")
+)
+
+// CommentHandler serves the /comments endpoints
+type CommentHandler struct {
+	comments repository.CommentRepository
+	uow      repository.UnitOfWork
+	maxDepth int // deepest nesting level of replies
+}
+
+func NewCommentHandler(comments repository.CommentRepository, uow repository.UnitOfWork, maxDepth int) *CommentHandler {
+	return &CommentHandler{comments: comments, uow: uow, maxDepth: maxDepth}
+}
 
 // @Summary Create a new comment
-// @Description Add a new comment to a specific post
+// @Description Add a new comment to a specific post, or a reply to one of its comments when parent_id is given
 // @Tags Comments
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
-// @Param request body Comment true "Comment data"
+// @Param request body model.Comment true "Comment data"
 // @Success 201 {object} map[string]interface{} "Comment created successfully"
-// @Failure 400 {object} map[string]string "Invalid input"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "Post or parent comment not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
 // @Router /comments [post]
-func CreateComment(c echo.Context) error {
-	authorID, _ := utils.GetUserIDFromToken(c)
-	if authorID == 0 {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"message": "not authorized"})
+func (h *CommentHandler) CreateComment(c echo.Context) error {
+	principal, ok := auth.CurrentUser(c)
+	if !ok {
+		return apperror.Unauthorized("not authorized")
 	}
+	authorID := principal.UserID
 
-	comment := new(Comment)
+	comment := new(model.Comment)
 	if err := c.Bind(comment); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "invalid request body"})
+		return apperror.BadRequest("invalid request body")
 	}
 
 	// Validate request fields
-	if err := validate.Struct(comment); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "invalid input"})
+	if err := c.Validate(comment); err != nil {
+		return err
 	}
 
 	comment.AuthorID = authorID
-	
-	// Log the user activity for creating a comment
-	description := "User commented on POST with ID " + strconv.Itoa(comment.PostID)
-	if logErr := activity_handler.LogActivity(c, authorID, description); logErr != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to log activity"})
-	}
+	comment.Depth = 0
 
-	// Insert comment into the database
-	query := `INSERT INTO comments (content, post_id, author_id) VALUES ($1, $2, $3) RETURNING id`
-	err := config.Pool.QueryRow(c.Request().Context(), query, comment.Content, comment.PostID, comment.AuthorID).Scan(&comment.ID)
+	// Insert the comment and log the activity in one transaction
+	err := h.uow.Do(c.Request().Context(), func(repos repository.Repositories) error {
+		description := "User commented on POST with ID " + strconv.Itoa(comment.PostID)
+
+		// replies live on the post of their parent, one level below it
+		if comment.ParentID != nil {
+			parent, err := repos.Comments.GetByID(c.Request().Context(), *comment.ParentID)
+			if errors.Is(err, repository.ErrNotFound) {
+				return errParentNotFound
+			}
+			if err != nil {
+				return err
+			}
+			if parent.PostID != comment.PostID {
+				return errParentMismatch
+			}
+			if parent.Depth >= h.maxDepth {
+				return errTooDeep
+			}
+			comment.Depth = parent.Depth + 1
+			description = "User replied to COMMENT with ID " + strconv.Itoa(parent.ID)
+		}
+
+		if err := repos.Comments.Create(c.Request().Context(), comment); err != nil {
+			return err
+		}
+		return repos.Activities.Log(c.Request().Context(), authorID, description)
+	})
+	if errors.Is(err, errParentNotFound) {
+		return apperror.NotFound("parent comment not found")
+	}
+	if errors.Is(err, errParentMismatch) {
+		return apperror.BadRequest("parent comment belongs to another post")
+	}
+	if errors.Is(err, errTooDeep) {
+		return apperror.BadRequest("replies cannot be nested more than " + strconv.Itoa(h.maxDepth) + " levels deep")
+	}
+	if errors.Is(err, repository.ErrInvalidReference) { // post_id names no post
+		return apperror.NotFound("post not found")
+	}
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to create comment"})
+		return apperror.Internal(err, "failed to create comment")
 	}
 
-
 	return c.JSON(http.StatusCreated, map[string]interface{}{
 		"message": "comment created successfully",
 		"comment": comment,
@@ -82,44 +134,198 @@ func CreateComment(c echo.Context) error {
 // @Param Authorization header string true "Bearer token"
 // @Param id path int true "Comment ID"
 // @Success 200 {object} map[string]interface{} "Comment details"
-// @Failure 400 {object} map[string]string "Invalid input"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 404 {object} map[string]string "Comment not found"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "Comment not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
 // @Router /comments/{id} [get]
-func GetCommentByID(c echo.Context) error {
+func (h *CommentHandler) GetCommentByID(c echo.Context) error {
 	commentID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "invalid comment ID"})
+		return apperror.BadRequest("invalid comment ID")
 	}
 
-	var comment Comment
-	var postTitle, authorName string
-	query := `
-		SELECT c.id, c.content, c.post_id, c.author_id, p.content AS post_title, u.full_name AS author_name
-		FROM comments c
-		JOIN posts p ON c.post_id = p.id
-		JOIN users u ON c.author_id = u.id
-		WHERE c.id = $1`
-	err = config.Pool.QueryRow(c.Request().Context(), query, commentID).Scan(
-		&comment.ID, &comment.Content, &comment.PostID, &comment.AuthorID, &postTitle, &authorName,
-	)
+	detail, err := h.comments.GetDetail(c.Request().Context(), commentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("comment not found")
+		}
+		return apperror.Internal(err, "failed to fetch comment")
+	}
 
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"comment": detail.Comment,
+		"post":    map[string]interface{}{"title": detail.PostTitle},
+		"author":  map[string]interface{}{"name": detail.AuthorName},
+	})
+}
+
+// @Summary Get the replies to a comment
+// @Description Retrieve a page of the direct replies to a comment, oldest first by default. Pass the returned next_cursor as cursor to get the following page; it is empty on the last page.
+// @Tags Comments
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Comment ID"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param order query string false "oldest (default), newest or most_liked"
+// @Success 200 {object} CommentListResponse "Page of replies"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "Comment not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /comments/{id}/replies [get]
+func (h *CommentHandler) GetReplies(c echo.Context) error {
+	commentID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		fmt.Println(err)
-		if err == sql.ErrNoRows {
-			return c.JSON(http.StatusNotFound, map[string]string{"message": "comment not found"})
+		return apperror.BadRequest("invalid comment ID")
+	}
+
+	order := c.QueryParam("order")
+	switch order {
+	case "":
+		order = repository.OrderOldest
+	case repository.OrderOldest, repository.OrderNewest, repository.OrderMostLiked:
+	default:
+		return apperror.BadRequest("order must be oldest, newest or most_liked")
+	}
+
+	page, err := pagination.Parse(c.QueryParam("limit"), c.QueryParam("cursor"), order)
+	if err != nil {
+		return apperror.BadRequest(err.Error())
+	}
+
+	if _, err := h.comments.GetByID(c.Request().Context(), commentID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("comment not found")
+		}
+		return apperror.Internal(err, "failed to fetch comment")
+	}
+
+	// one extra reply tells whether there is a next page
+	replies, err := h.comments.List(c.Request().Context(), repository.CommentFilter{
+		ParentID: commentID,
+		Order:    order,
+		Limit:    page.Limit + 1,
+		After:    page.After,
+	})
+	if err != nil {
+		return apperror.Internal(err, "failed to fetch replies")
+	}
+
+	replies, next := pagination.Page(replies, page, repository.CommentCursor(order))
+	return c.JSON(http.StatusOK, CommentListResponse{Comments: replies, NextCursor: next})
+}
+
+// @Summary Edit a comment
+// @Description Change the content of a comment (author or moderator). The replaced version is kept as a revision.
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Comment ID"
+// @Param request body UpdateCommentRequest true "New comment content"
+// @Success 200 {object} map[string]interface{} "Comment updated successfully"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Comment not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /comments/{id} [patch]
+func (h *CommentHandler) UpdateComment(c echo.Context) error {
+	commentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("invalid comment ID")
+	}
+
+	principal, ok := auth.CurrentUser(c)
+	if !ok {
+		return apperror.Unauthorized("not authorized")
+	}
+	editorID := principal.UserID
+
+	req := new(UpdateCommentRequest)
+	if err := c.Bind(req); err != nil {
+		return apperror.BadRequest("invalid request body")
+	}
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	// Only the owner of the comment or a moderator may edit it; save it and
+	// log the activity in one transaction
+	var comment *model.Comment
+	ctx := c.Request().Context()
+	err = h.uow.Do(ctx, func(repos repository.Repositories) error {
+		current, err := repos.Comments.GetByID(ctx, commentID)
+		if err != nil {
+			return err
+		}
+		if !auth.Can(principal, auth.EditComment, current.AuthorID) {
+			return errForbidden
+		}
+
+		comment = current
+		if req.Content == current.Content {
+			// nothing changed, no revision to keep
+			return nil
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to fetch comment"})
+		comment.Content = req.Content
+		if err := repos.Comments.Update(ctx, comment, editorID); err != nil {
+			return err
+		}
+		description := "User edited COMMENT with ID " + strconv.Itoa(commentID)
+		return repos.Activities.Log(ctx, editorID, description)
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound("comment not found")
+	}
+	if errors.Is(err, errForbidden) {
+		return apperror.Forbidden("you are not authorized to edit this comment")
+	}
+	if err != nil {
+		return apperror.Internal(err, "failed to update comment")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "comment updated successfully",
 		"comment": comment,
-		"post":    map[string]interface{}{"title": postTitle},
-		"author":  map[string]interface{}{"name": authorName},
 	})
 }
 
+// @Summary Get the revisions of a comment
+// @Description Retrieve the previous versions of an edited comment, oldest first
+// @Tags Comments
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Comment ID"
+// @Success 200 {array} model.CommentRevision "List of revisions"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "Comment not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /comments/{id}/revisions [get]
+func (h *CommentHandler) GetCommentRevisions(c echo.Context) error {
+	commentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("invalid comment ID")
+	}
+
+	if _, err := h.comments.GetByID(c.Request().Context(), commentID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("comment not found")
+		}
+		return apperror.Internal(err, "failed to fetch comment")
+	}
+
+	revisions, err := h.comments.ListRevisions(c.Request().Context(), commentID)
+	if err != nil {
+		return apperror.Internal(err, "failed to fetch revisions")
+	}
+
+	return c.JSON(http.StatusOK, revisions)
+}
+
 // @Summary Delete a comment by ID
 // @Description Remove a specific comment by its ID
 // @Tags Comments
@@ -127,44 +333,126 @@ func GetCommentByID(c echo.Context) error {
 // @Param Authorization header string true "Bearer token"
 // @Param id path int true "Comment ID"
 // @Success 200 {object} map[string]string "Comment deleted successfully"
-// @Failure 400 {object} map[string]string "Invalid input"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 403 {object} map[string]string "Forbidden"
-// @Failure 404 {object} map[string]string "Comment not found"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Comment not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
 // @Router /comments/{id} [delete]
-func DeleteCommentByID(c echo.Context) error {
+func (h *CommentHandler) DeleteCommentByID(c echo.Context) error {
 	commentID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "invalid comment ID"})
+		return apperror.BadRequest("invalid comment ID")
 	}
 
-	authorID, _ := utils.GetUserIDFromToken(c)
-	if authorID == 0 {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"message": "not authorized"})
+	principal, ok := auth.CurrentUser(c)
+	if !ok {
+		return apperror.Unauthorized("not authorized")
 	}
+	authorID := principal.UserID
 
-	// Check if the user is the owner of the comment
-	var commentOwnerID int
-	query := `SELECT author_id FROM comments WHERE id = $1`
-	err = config.Pool.QueryRow(c.Request().Context(), query, commentID).Scan(&commentOwnerID)
+	// Only the owner of the comment or a moderator may delete it
+	comment, err := h.comments.GetByID(c.Request().Context(), commentID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return c.JSON(http.StatusNotFound, map[string]string{"message": "comment not found"})
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("comment not found")
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to validate ownership"})
+		return apperror.Internal(err, "failed to validate ownership")
 	}
 
-	if commentOwnerID != authorID {
-		return c.JSON(http.StatusForbidden, map[string]string{"message": "you are not authorized to delete this comment"})
+	if !auth.Can(principal, auth.DeleteComment, comment.AuthorID) {
+		return apperror.Forbidden("you are not authorized to delete this comment")
 	}
 
-	// Delete the comment
-	queryDelete := `DELETE FROM comments WHERE id = $1`
-	_, err = config.Pool.Exec(c.Request().Context(), queryDelete, commentID)
+	// Delete the comment and log the activity in one transaction
+	err = h.uow.Do(c.Request().Context(), func(repos repository.Repositories) error {
+		if err := repos.Comments.Delete(c.Request().Context(), commentID); err != nil {
+			return err
+		}
+		description := "User deleted COMMENT with ID " + strconv.Itoa(commentID)
+		return repos.Activities.Log(c.Request().Context(), authorID, description)
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound("comment not found")
+	}
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to delete comment"})
+		return apperror.Internal(err, "failed to delete comment")
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{"message": "comment deleted successfully"})
-}
\ No newline at end of file
+}
+
+// @Summary Like a comment
+// @Description Like a comment, liking it again has no effect
+// @Tags Comments
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Comment ID"
+// @Success 200 {object} map[string]interface{} "Comment liked"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "Comment not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /comments/{id}/like [post]
+func (h *CommentHandler) LikeComment(c echo.Context) error {
+	return h.setLike(c, true)
+}
+
+// @Summary Unlike a comment
+// @Description Remove your like from a comment, unliking it again has no effect
+// @Tags Comments
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Comment ID"
+// @Success 200 {object} map[string]interface{} "Comment unliked"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "Comment not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /comments/{id}/like [delete]
+func (h *CommentHandler) UnlikeComment(c echo.Context) error {
+	return h.setLike(c, false)
+}
+
+// setLike adds or removes the principal's like and logs it when something changed
+func (h *CommentHandler) setLike(c echo.Context, like bool) error {
+	commentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("invalid comment ID")
+	}
+
+	principal, ok := auth.CurrentUser(c)
+	if !ok {
+		return apperror.Unauthorized("not authorized")
+	}
+	userID := principal.UserID
+
+	var comment *model.Comment
+	ctx := c.Request().Context()
+	load := func(repos repository.Repositories) (err error) {
+		comment, err = repos.Comments.GetByID(ctx, commentID)
+		return err
+	}
+	description := "User liked COMMENT with ID " + strconv.Itoa(commentID)
+	toggle := func(repos repository.Repositories) (bool, error) {
+		return repos.Comments.Like(ctx, commentID, userID)
+	}
+	if !like {
+		description = "User unliked COMMENT with ID " + strconv.Itoa(commentID)
+		toggle = func(repos repository.Repositories) (bool, error) {
+			return repos.Comments.Unlike(ctx, commentID, userID)
+		}
+	}
+	err = repository.Toggle(ctx, h.uow, userID, description, load, toggle)
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound("comment not found")
+	}
+	if err != nil {
+		return apperror.Internal(err, "failed to update like")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"liked":      like,
+		"like_count": comment.LikeCount,
+	})
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"w3/gc3/internal/auth"
+	"w3/gc3/internal/handlertest"
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository/memory"
+)
+
+// newServer serves the comment routes to the user with userID and roles,
+// replies nest at most 2 levels deep
+func newServer(store *memory.Store, userID int, roles ...string) *echo.Echo {
+	h := NewCommentHandler(store.Repositories().Comments, store, 2)
+
+	e := handlertest.NewEcho()
+	as := handlertest.As(userID, roles...)
+	e.POST("/comments", h.CreateComment, as)
+	e.GET("/comments/:id", h.GetCommentByID, as)
+	e.GET("/comments/:id/replies", h.GetReplies, as)
+	e.PATCH("/comments/:id", h.UpdateComment, as)
+	e.DELETE("/comments/:id", h.DeleteCommentByID, as)
+	e.POST("/comments/:id/like", h.LikeComment, as)
+	e.POST("/comments/:id/reactions", h.AddReaction, as)
+	e.DELETE("/comments/:id/reactions/:reaction", h.RemoveReaction, as)
+	e.DELETE("/comments/:id/like", h.UnlikeComment, as)
+	return e
+}
+
+func createPost(t *testing.T, store *memory.Store, userID int) int {
+	t.Helper()
+	post := &model.Post{Content: "hello", ImageURL: "https://example.com/a.png", UserID: userID}
+	if err := store.Repositories().Posts.Create(context.Background(), post); err != nil {
+		t.Fatal(err)
+	}
+	return post.ID
+}
+
+func TestCreateComment(t *testing.T) {
+	store := memory.NewStore()
+	alice := handlertest.CreateUser(t, store, "alice")
+	e := newServer(store, alice.ID)
+	post := strconv.Itoa(createPost(t, store, alice.ID))
+	other := strconv.Itoa(createPost(t, store, alice.ID))
+
+	// 1 is top-level, 2 replies to 1 and 3 to 2, the deepest allowed
+	for _, parent := range []string{"null", "1", "2"} {
+		resp := handlertest.Do(t, e, "POST", "/comments", `{"post_id":`+post+`,"parent_id":`+parent+`,"content":"hi"}`)
+		if resp.Code != 201 {
+			t.Fatalf("parent %s: status %d: %v", parent, resp.Code, resp.Body)
+		}
+	}
+	resp := handlertest.Do(t, e, "GET", "/comments/1/replies", "")
+	if resp.Code != 200 {
+		t.Fatalf("replies: status %d: %v", resp.Code, resp.Body)
+	}
+	if replies := resp.Body["comments"].([]any); len(replies) != 1 || replies[0].(map[string]any)["id"] != float64(2) {
+		t.Errorf("replies of 1: %v, want comment 2 only", replies)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		code int
+		err  string
+	}{
+		{"missing content", `{"post_id":` + post + `}`, 400, "validation_failed"},
+		{"blank content", `{"post_id":` + post + `,"content":"   "}`, 400, "validation_failed"},
+		{"missing post", `{"post_id":999,"content":"hi"}`, 404, "not_found"},
+		{"missing parent", `{"post_id":` + post + `,"parent_id":999,"content":"hi"}`, 404, "not_found"},
+		{"parent on another post", `{"post_id":` + other + `,"parent_id":1,"content":"hi"}`, 400, "bad_request"},
+		{"too deep", `{"post_id":` + post + `,"parent_id":3,"content":"hi"}`, 400, "bad_request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := handlertest.Do(t, e, "POST", "/comments", tt.body)
+			if resp.Code != tt.code || resp.ErrorCode() != tt.err {
+				t.Errorf("status %d code %q, want %d %q", resp.Code, resp.ErrorCode(), tt.code, tt.err)
+			}
+		})
+	}
+}
+
+func TestUpdateComment(t *testing.T) {
+	store := memory.NewStore()
+	alice := handlertest.CreateUser(t, store, "alice")
+	bob := handlertest.CreateUser(t, store, "bob")
+	mod := handlertest.CreateUser(t, store, "mod")
+	post := strconv.Itoa(createPost(t, store, alice.ID))
+	asAlice := newServer(store, alice.ID)
+	handlertest.Do(t, asAlice, "POST", "/comments", `{"post_id":`+post+`,"content":"first"}`)
+
+	if resp := handlertest.Do(t, newServer(store, bob.ID), "PATCH", "/comments/1", `{"content":"hijacked"}`); resp.Code != 403 {
+		t.Fatalf("other user: status %d, want 403", resp.Code)
+	}
+	if resp := handlertest.Do(t, asAlice, "PATCH", "/comments/1", `{"content":" "}`); resp.Code != 400 {
+		t.Errorf("blank content: status %d, want 400", resp.Code)
+	}
+
+	resp := handlertest.Do(t, asAlice, "PATCH", "/comments/1", `{"content":"second"}`)
+	if resp.Code != 200 {
+		t.Fatalf("author: status %d, want 200: %v", resp.Code, resp.Body)
+	}
+	comment := resp.Body["comment"].(map[string]any)
+	if comment["content"] != "second" || comment["version"] != float64(2) || comment["edited"] != true {
+		t.Errorf("comment after edit: %v", comment)
+	}
+
+	// moderators may edit any comment, the revision records who did
+	asMod := newServer(store, mod.ID, auth.RolesFor(auth.RoleModerator)...)
+	if resp := handlertest.Do(t, asMod, "PATCH", "/comments/1", `{"content":"moderated"}`); resp.Code != 200 {
+		t.Fatalf("moderator: status %d, want 200: %v", resp.Code, resp.Body)
+	}
+
+	revisions, err := store.Repositories().Comments.ListRevisions(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("%d revisions, want 2: %+v", len(revisions), revisions)
+	}
+	first, second := revisions[0], revisions[1]
+	if first.Version != 1 || first.Content != "first" || first.ReplacedBy == nil || *first.ReplacedBy != alice.ID {
+		t.Errorf("first revision %+v, want version 1 replaced by alice", first)
+	}
+	if second.Version != 2 || second.Content != "second" || second.ReplacedBy == nil || *second.ReplacedBy != mod.ID {
+		t.Errorf("second revision %+v, want version 2 replaced by mod", second)
+	}
+
+	if resp := handlertest.Do(t, asAlice, "PATCH", "/comments/999", `{"content":"x"}`); resp.Code != 404 {
+		t.Errorf("missing comment: status %d, want 404", resp.Code)
+	}
+}
+
+func TestDeleteComment(t *testing.T) {
+	store := memory.NewStore()
+	alice := handlertest.CreateUser(t, store, "alice")
+	bob := handlertest.CreateUser(t, store, "bob")
+	post := strconv.Itoa(createPost(t, store, alice.ID))
+	asAlice := newServer(store, alice.ID)
+	handlertest.Do(t, asAlice, "POST", "/comments", `{"post_id":`+post+`,"content":"hi"}`)
+
+	if resp := handlertest.Do(t, newServer(store, bob.ID), "DELETE", "/comments/1", ""); resp.Code != 403 {
+		t.Fatalf("other user: status %d, want 403", resp.Code)
+	}
+	if resp := handlertest.Do(t, asAlice, "DELETE", "/comments/1", ""); resp.Code != 200 {
+		t.Fatalf("author: status %d, want 200: %v", resp.Code, resp.Body)
+	}
+	if resp := handlertest.Do(t, asAlice, "GET", "/comments/1", ""); resp.Code != 404 {
+		t.Errorf("deleted comment: status %d, want 404", resp.Code)
+	}
+}
+
+func TestLikeComment(t *testing.T) {
+	store := memory.NewStore()
+	alice := handlertest.CreateUser(t, store, "alice")
+	post := strconv.Itoa(createPost(t, store, alice.ID))
+	e := newServer(store, alice.ID)
+	handlertest.Do(t, e, "POST", "/comments", `{"post_id":`+post+`,"content":"hi"}`)
+
+	steps := []struct {
+		method string
+		liked  bool
+		count  float64
+	}{
+		{"POST", true, 1},
+		{"POST", true, 1}, // liking again has no effect
+		{"DELETE", false, 0},
+		{"DELETE", false, 0},
+	}
+	for _, step := range steps {
+		resp := handlertest.Do(t, e, step.method, "/comments/1/like", "")
+		if resp.Code != 200 || resp.Body["liked"] != step.liked || resp.Body["like_count"] != step.count {
+			t.Fatalf("%s /comments/1/like: status %d: %v", step.method, resp.Code, resp.Body)
+		}
+	}
+
+	// only the like and the unlike that changed something are logged,
+	// next to the comment creation
+	activities, err := store.Repositories().Activities.ListByUser(context.Background(), alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(activities) != 3 {
+		t.Errorf("%d activities, want 3: %v", len(activities), activities)
+	}
+
+	if resp := handlertest.Do(t, e, "POST", "/comments/99/like", ""); resp.Code != 404 {
+		t.Errorf("like missing comment: status %d, want 404", resp.Code)
+	}
+}
+
+func TestReactions(t *testing.T) {
+	store := memory.NewStore()
+	alice := handlertest.CreateUser(t, store, "alice")
+	post := strconv.Itoa(createPost(t, store, alice.ID))
+	e := newServer(store, alice.ID)
+	handlertest.Do(t, e, "POST", "/comments", `{"post_id":`+post+`,"content":"hi"}`)
+
+	for i := 0; i < 2; i++ { // adding twice counts once
+		resp := handlertest.Do(t, e, "POST", "/comments/1/reactions", `{"reaction":"heart"}`)
+		if resp.Code != 200 {
+			t.Fatalf("add: status %d: %v", resp.Code, resp.Body)
+		}
+		reactions := resp.Body["reactions"].(map[string]any)
+		if reactions["heart"] != float64(1) {
+			t.Fatalf("reactions %v, want one heart", reactions)
+		}
+	}
+
+	resp := handlertest.Do(t, e, "POST", "/comments/1/reactions", `{"reaction":"poop"}`)
+	if resp.Code != 400 {
+		t.Errorf("unknown reaction: status %d, want 400", resp.Code)
+	}
+
+	resp = handlertest.Do(t, e, "DELETE", "/comments/1/reactions/heart", "")
+	if resp.Code != 200 || resp.Body["reactions"].(map[string]any)["heart"] != nil {
+		t.Errorf("remove: status %d: %v", resp.Code, resp.Body)
+	}
+}
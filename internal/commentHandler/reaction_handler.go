@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"w3/gc3/internal/apperror"
+	"w3/gc3/internal/auth"
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReactionRequest struct
+type ReactionRequest struct {
+	Reaction string `json:"reaction" validate:"required"`
+}
+
+// @Summary React to a comment
+// @Description React to a comment with one of thumbs_up, heart, laugh, wow, sad or angry. A user may add several different reactions to the same comment, adding one again has no effect. Responds with the aggregated reaction counts of the comment.
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Comment ID"
+// @Param request body ReactionRequest true "Reaction name"
+// @Success 200 {object} map[string]interface{} "Reaction added"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "Comment not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /comments/{id}/reactions [post]
+func (h *CommentHandler) AddReaction(c echo.Context) error {
+	req := new(ReactionRequest)
+	if err := c.Bind(req); err != nil {
+		return apperror.BadRequest("invalid request body")
+	}
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+	return h.setReaction(c, req.Reaction, true)
+}
+
+// @Summary Remove a reaction from a comment
+// @Description Remove one of your reactions (thumbs_up, heart, laugh, wow, sad or angry) from a comment, removing it again has no effect. Responds with the aggregated reaction counts of the comment.
+// @Tags Comments
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Comment ID"
+// @Param reaction path string true "Reaction name" Enums(thumbs_up, heart, laugh, wow, sad, angry)
+// @Success 200 {object} map[string]interface{} "Reaction removed"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "Comment not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /comments/{id}/reactions/{reaction} [delete]
+func (h *CommentHandler) RemoveReaction(c echo.Context) error {
+	return h.setReaction(c, c.Param("reaction"), false)
+}
+
+// setReaction adds or removes one of the principal's reactions and logs it
+// when something changed
+func (h *CommentHandler) setReaction(c echo.Context, reaction string, add bool) error {
+	commentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("invalid comment ID")
+	}
+	if !model.ValidReaction(reaction) {
+		return apperror.BadRequest("reaction must be one of " + strings.Join(model.ReactionNames(), ", "))
+	}
+
+	principal, ok := auth.CurrentUser(c)
+	if !ok {
+		return apperror.Unauthorized("not authorized")
+	}
+	userID := principal.UserID
+
+	var comment *model.Comment
+	ctx := c.Request().Context()
+	load := func(repos repository.Repositories) (err error) {
+		comment, err = repos.Comments.GetByID(ctx, commentID)
+		return err
+	}
+	description := "User reacted with " + reaction + " to COMMENT with ID " + strconv.Itoa(commentID)
+	toggle := func(repos repository.Repositories) (bool, error) {
+		return repos.Comments.AddReaction(ctx, commentID, userID, reaction)
+	}
+	if !add {
+		description = "User removed the " + reaction + " reaction from COMMENT with ID " + strconv.Itoa(commentID)
+		toggle = func(repos repository.Repositories) (bool, error) {
+			return repos.Comments.RemoveReaction(ctx, commentID, userID, reaction)
+		}
+	}
+	err = repository.Toggle(ctx, h.uow, userID, description, load, toggle)
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound("comment not found")
+	}
+	if err != nil {
+		return apperror.Internal(err, "failed to update reaction")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"reactions": comment.Reactions,
+	})
+}
@@ -0,0 +1,63 @@
+package content
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"w3/gc3/internal/httpclient"
+)
+
+// APINinjas fetches random jokes from the api-ninjas jokes API
+type APINinjas struct {
+	url     string
+	key     string
+	timeout time.Duration
+	client  httpclient.Doer
+}
+
+// NewAPINinjas returns a provider calling url with key through client, giving
+// up on a call with all its retries after timeout
+func NewAPINinjas(url, key string, timeout time.Duration, client httpclient.Doer) *APINinjas {
+	return &APINinjas{url: url, key: key, timeout: timeout, client: client}
+}
+
+func (a *APINinjas) Random(ctx context.Context) (string, error) {
+	if a.key == "" {
+		return "", errors.New("api-ninjas: no API key configured")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.url, nil)
+	if err != nil {
+		return "", fmt.Errorf("api-ninjas: failed to create request: %w", err)
+	}
+	req.Header.Set("X-Api-Key", a.key)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("api-ninjas: failed to fetch a joke: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("api-ninjas: received status %d", resp.StatusCode)
+	}
+
+	// the API answers with an array of jokes, of one by default
+	var jokes []struct {
+		Joke string `json:"joke"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jokes); err != nil {
+		return "", fmt.Errorf("api-ninjas: failed to parse response: %w", err)
+	}
+	if len(jokes) == 0 || jokes[0].Joke == "" {
+		return "", errors.New("api-ninjas: no joke in the response")
+	}
+	return jokes[0].Joke, nil
+}
@@ -0,0 +1,58 @@
+// Package content supplies the text of posts created without any
+package content
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"w3/gc3/config/settings"
+	"w3/gc3/internal/httpclient"
+)
+
+// ContentProvider returns a random joke or quote
+type ContentProvider interface {
+	Random(ctx context.Context) (string, error)
+}
+
+// New builds the provider selected by cfg, calling APIs as outbound says. The
+// api_ninjas provider falls back to the jokes file and the file to the static
+// text, so only the static text is ever missing from the chain.
+func New(cfg settings.ContentConfig, outbound settings.OutboundConfig) (ContentProvider, error) {
+	static := Static(cfg.StaticText)
+	if cfg.Provider == settings.ContentStatic {
+		return static, nil
+	}
+
+	file, err := NewFile(cfg.File)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Provider == settings.ContentFile {
+		return Fallback{file, static}, nil
+	}
+
+	client := httpclient.New(settings.ContentAPINinjas, time.Duration(cfg.APINinjas.Timeout), outbound)
+	api := NewAPINinjas(cfg.APINinjas.URL, cfg.APINinjas.Key, time.Duration(cfg.APINinjas.TotalTimeout), client)
+	return Fallback{api, file, static}, nil
+}
+
+// Fallback asks each provider in turn until one of them succeeds
+type Fallback []ContentProvider
+
+func (f Fallback) Random(ctx context.Context) (string, error) {
+	var errs []error
+	for _, provider := range f {
+		text, err := provider.Random(ctx)
+		if err == nil {
+			return text, nil
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		log.Printf("Content provider failed, falling back: %v", err)
+		errs = append(errs, err)
+	}
+	return "", errors.Join(append(errs, errors.New("no content provider succeeded"))...)
+}
@@ -0,0 +1,127 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"w3/gc3/config/settings"
+)
+
+func TestAPINinjas(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{"joke", http.StatusOK, `[{"joke":"a joke"}]`, "a joke", false},
+		{"server error", http.StatusInternalServerError, `{"error":"down"}`, "", true},
+		{"malformed body", http.StatusOK, `{"joke":`, "", true},
+		{"no joke", http.StatusOK, `[]`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("X-Api-Key") != "key" {
+					t.Errorf("X-Api-Key %q, want key", r.Header.Get("X-Api-Key"))
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			got, err := NewAPINinjas(server.URL, "key", time.Second, server.Client()).Random(context.Background())
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Random() = %q, %v, want %q, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := NewAPINinjas("http://127.0.0.1:1", "", time.Second, http.DefaultClient).Random(context.Background()); err == nil {
+		t.Error("Random() without a key succeeded")
+	}
+}
+
+// the timeout bounds the whole call, not only each attempt of the client
+func TestAPINinjasTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	_, err := NewAPINinjas(server.URL, "key", 50*time.Millisecond, server.Client()).Random(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Errorf("Random() = %v after %v, want context.DeadlineExceeded after 50ms", err, time.Since(start))
+	}
+}
+
+// failing is a provider that always fails, counting its calls
+type failing struct{ calls *int }
+
+func (f failing) Random(ctx context.Context) (string, error) {
+	*f.calls++
+	return "", errors.New("failed")
+}
+
+func TestFallback(t *testing.T) {
+	var calls int
+	got, err := Fallback{failing{&calls}, Static("second"), Static("third")}.Random(context.Background())
+	if err != nil || got != "second" || calls != 1 {
+		t.Errorf("Random() = %q, %v after %d failed calls, want second after 1", got, err, calls)
+	}
+
+	calls = 0
+	if _, err := (Fallback{failing{&calls}, failing{&calls}}).Random(context.Background()); err == nil || calls != 2 {
+		t.Errorf("all failing: %v after %d calls, want an error after 2", err, calls)
+	}
+
+	// once the caller gives up the rest of the chain is not asked
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls = 0
+	if _, err := (Fallback{failing{&calls}, Static("second")}).Random(ctx); !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("cancelled: %v after %d calls, want context.Canceled after 1", err, calls)
+	}
+}
+
+func TestNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jokes.txt")
+	if err := os.WriteFile(path, []byte("# a comment\n\nfrom the file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// nothing listens on port 1, so api_ninjas falls back to the file
+	api := settings.APINinjasConfig{URL: "http://127.0.0.1:1", Key: "key", Timeout: settings.Duration(time.Second), TotalTimeout: settings.Duration(time.Second)}
+	outbound := settings.OutboundConfig{BreakerThreshold: 5, BreakerCooldown: settings.Duration(time.Minute)}
+
+	tests := []struct {
+		provider string
+		want     string
+	}{
+		{settings.ContentStatic, "static"},
+		{settings.ContentFile, "from the file"},
+		{settings.ContentAPINinjas, "from the file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			provider, err := New(settings.ContentConfig{Provider: tt.provider, APINinjas: api, File: path, StaticText: "static"}, outbound)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := provider.Random(context.Background()); err != nil || got != tt.want {
+				t.Errorf("Random() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := New(settings.ContentConfig{Provider: settings.ContentFile, File: filepath.Join(t.TempDir(), "missing.txt")}, outbound); err == nil {
+		t.Error("New with a missing file succeeded")
+	}
+}
@@ -0,0 +1,54 @@
+package content
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strings"
+)
+
+//go:embed jokes.txt
+var bundled []byte
+
+// File picks random entries of a jokes and quotes file
+type File struct {
+	entries []string
+}
+
+// NewFile reads the file at path, one entry per line with blank lines and
+// lines starting with # skipped. An empty path reads the bundled file.
+func NewFile(path string) (*File, error) {
+	data := bundled
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read content file: %w", err)
+		}
+	}
+
+	var entries []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read content file: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("content file has no entries")
+	}
+	return &File{entries: entries}, nil
+}
+
+func (f *File) Random(ctx context.Context) (string, error) {
+	return f.entries[rand.IntN(len(f.entries))], nil
+}
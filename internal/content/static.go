@@ -0,0 +1,10 @@
+package content
+
+import "context"
+
+// Static always returns the same text, the last resort of a Fallback
+type Static string
+
+func (s Static) Random(ctx context.Context) (string, error) {
+	return string(s), nil
+}
@@ -0,0 +1,117 @@
+// Package handlertest serves handlers the way main does, on top of the
+// in-memory store, for the tests of the handler packages
+package handlertest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/crypto/bcrypt"
+
+	"w3/gc3/config/settings"
+	"w3/gc3/internal/apperror"
+	"w3/gc3/internal/auth"
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository/memory"
+	"w3/gc3/internal/token"
+	"w3/gc3/internal/validation"
+)
+
+// Password of the users made by CreateUser
+const Password = "secret123"
+
+// NewEcho returns an echo instance set up like the one of main
+func NewEcho() *echo.Echo {
+	e := echo.New()
+	e.Validator = validation.New()
+	e.HTTPErrorHandler = apperror.HTTPErrorHandler
+	e.Use(middleware.RequestID())
+	return e
+}
+
+// NewTokens returns a token service signing with a single test key
+func NewTokens(t testing.TB) *token.Service {
+	t.Helper()
+	tokens, err := token.NewService(settings.JWTConfig{
+		Keys:       []settings.SigningKey{{ID: "test", Secret: strings.Repeat("s", 32)}},
+		TTL:        settings.Duration(time.Minute),
+		RefreshTTL: settings.Duration(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokens
+}
+
+// As authenticates every request as the user with userID, in place of the
+// JWT middleware. Without roles the user gets the ones of a regular user
+func As(userID int, roles ...string) echo.MiddlewareFunc {
+	if len(roles) == 0 {
+		roles = auth.RolesFor(auth.RoleUser)
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			auth.SetPrincipal(c, &auth.Principal{UserID: userID, Roles: roles})
+			return next(c)
+		}
+	}
+}
+
+// CreateUser stores a user called username, with Password as its password
+func CreateUser(t testing.TB, store *memory.Store, username string) *model.User {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(Password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &model.User{
+		FullName: "User " + username,
+		Email:    username + "@example.com",
+		Username: username,
+		Password: string(hash),
+		Age:      30,
+	}
+	if err := store.Repositories().Users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// Response is a recorded response with its JSON body decoded
+type Response struct {
+	Code int
+	Body map[string]any
+}
+
+// Do sends a request with a JSON body to e, headers alternating names and values
+func Do(t testing.TB, e *echo.Echo, method, path, body string, headers ...string) Response {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	resp := Response{Code: rec.Code}
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp.Body); err != nil {
+			t.Fatalf("%s %s: invalid JSON response %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return resp
+}
+
+// ErrorCode returns the code of an error response, empty for other responses
+func (r Response) ErrorCode() string {
+	body, _ := r.Body["error"].(map[string]any)
+	code, _ := body["code"].(string)
+	return code
+}
@@ -0,0 +1,100 @@
+package httpclient
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// state of a breaker
+type state string
+
+const (
+	closed   state = "closed"    // calls go through
+	open     state = "open"      // calls fail right away until the cooldown is over
+	halfOpen state = "half_open" // one call goes through to find out if the API is back
+)
+
+// breaker is a circuit breaker: it opens after threshold consecutive failed
+// calls and lets a single trial call through once cooldown has passed, which
+// closes it again when it succeeds
+type breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    state
+	failures int
+	openedAt time.Time
+	trying   bool // the trial call of a half open breaker is in flight
+}
+
+func newBreaker(name string, threshold int, cooldown time.Duration) *breaker {
+	return &breaker{name: name, threshold: threshold, cooldown: cooldown, state: closed}
+}
+
+// allow reports whether a call may go through, callers that are allowed
+// must report how it went with success, failure or release
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case open:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = halfOpen
+		fallthrough
+	case halfOpen:
+		if b.trying {
+			return false
+		}
+		b.trying = true
+	}
+	return true
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != closed {
+		log.Printf("Circuit breaker for %s closed", b.name)
+	}
+	b.state = closed
+	b.failures = 0
+	b.trying = false
+}
+
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trying = false
+	if b.state == halfOpen || (b.state == closed && b.failures >= b.threshold) {
+		if b.state == closed {
+			log.Printf("Circuit breaker for %s opened after %d failed calls", b.name, b.failures)
+		}
+		b.state = open
+		b.openedAt = time.Now()
+	}
+}
+
+// release ends a call that neither succeeded nor failed
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trying = false
+}
+
+// current returns the state of the breaker
+func (b *breaker) current() state {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
@@ -0,0 +1,163 @@
+// Package httpclient is the client of every call to a third-party API: each
+// attempt has its own timeout, calls failing with 429, 5xx or a network error
+// are retried with jittered backoff and an API that keeps failing is not
+// called at all for a while
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"w3/gc3/config/settings"
+)
+
+// ErrCircuitOpen is returned instead of calling an API whose circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Doer sends HTTP requests, satisfied by *Client and *http.Client
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client sends the requests to one third-party API
+type Client struct {
+	name    string
+	timeout time.Duration
+	cfg     settings.OutboundConfig
+	http    *http.Client
+	breaker *breaker
+	metrics *metrics
+}
+
+// New returns the client of the API called name, which names its metrics,
+// giving every attempt timeout to complete
+func New(name string, timeout time.Duration, cfg settings.OutboundConfig) *Client {
+	b := newBreaker(name, cfg.BreakerThreshold, time.Duration(cfg.BreakerCooldown))
+	return &Client{
+		name:    name,
+		timeout: timeout,
+		cfg:     cfg,
+		http:    &http.Client{},
+		breaker: b,
+		metrics: newMetrics(name, b),
+	}
+}
+
+// Do sends req, retrying it as configured. The attempts stop as soon as the
+// context of req is done, so callers bound the whole call with it. Responses
+// with any other status are returned as they are, their body must be closed.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if !c.breaker.allow() {
+		c.metrics.shortCircuits.Add(1)
+		return nil, fmt.Errorf("%s: %w", c.name, ErrCircuitOpen)
+	}
+
+	resp, err := c.retry(req)
+	switch {
+	case req.Context().Err() != nil:
+		// the caller gave up, which says nothing about the API
+		c.breaker.release()
+	case retryable(resp, err):
+		c.breaker.failure()
+	default:
+		c.breaker.success()
+	}
+	return resp, err
+}
+
+func (c *Client) retry(req *http.Request) (*http.Response, error) {
+	// a body that cannot be read again cannot be sent again
+	rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.attempt(req, attempt)
+		if !retryable(resp, err) || !rewindable || attempt >= c.cfg.MaxRetries || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		wait := c.backoff(attempt, resp)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		c.metrics.retries.Add(1)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends req once, within the client's timeout
+func (c *Client) attempt(req *http.Request, n int) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), c.timeout)
+	try := req.Clone(ctx)
+	if n > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		try.Body = body
+	}
+
+	start := time.Now()
+	resp, err := c.http.Do(try)
+	// like the breaker, the metrics leave out attempts the caller gave up on
+	if req.Context().Err() == nil {
+		c.metrics.observe(time.Since(start), retryable(resp, err))
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// the timeout covers reading the body too, until the caller closes it
+	resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// backoff is how long to wait before retrying after attempt: the retry
+// backoff doubled on every attempt, or what the response asks for in
+// Retry-After, never more than the max retry backoff, with half of it jittered
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	maxWait := time.Duration(c.cfg.MaxRetryBackoff)
+	wait := time.Duration(c.cfg.RetryBackoff) << attempt
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			wait = time.Duration(seconds) * time.Second
+		}
+	}
+	if wait <= 0 || wait > maxWait { // <= 0 when the shift overflows
+		wait = maxWait
+	}
+	return wait/2 + rand.N(wait/2+1)
+}
+
+// retryable reports whether an attempt failed in a way worth retrying
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,240 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"w3/gc3/config/settings"
+)
+
+// upstream answers with the statuses in turn, then with 200 forever
+type upstream struct {
+	statuses   []int
+	retryAfter string
+	calls      atomic.Int32
+}
+
+func (u *upstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := int(u.calls.Add(1)) - 1
+	if n < len(u.statuses) {
+		if u.retryAfter != "" {
+			w.Header().Set("Retry-After", u.retryAfter)
+		}
+		w.WriteHeader(u.statuses[n])
+		return
+	}
+	io.WriteString(w, "ok")
+}
+
+func newClient(t *testing.T, name string, cfg settings.OutboundConfig) *Client {
+	t.Helper()
+	if cfg.RetryBackoff == 0 {
+		cfg.RetryBackoff = settings.Duration(time.Millisecond)
+	}
+	if cfg.MaxRetryBackoff == 0 {
+		cfg.MaxRetryBackoff = settings.Duration(5 * time.Millisecond)
+	}
+	if cfg.BreakerThreshold == 0 {
+		cfg.BreakerThreshold = 100
+	}
+	if cfg.BreakerCooldown == 0 {
+		cfg.BreakerCooldown = settings.Duration(time.Minute)
+	}
+	return New(name+"_"+t.Name(), time.Second, cfg)
+}
+
+func get(t *testing.T, c *Client, ctx context.Context, url string) (int, error) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, nil
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		retries  int
+		want     int
+		calls    int32
+	}{
+		{"success", nil, 2, 200, 1},
+		{"5xx then success", []int{503, 500}, 2, 200, 3},
+		{"429 then success", []int{429}, 2, 200, 2},
+		{"retries exhausted", []int{502, 502, 502}, 2, 502, 3},
+		{"retries disabled", []int{503}, 0, 503, 1},
+		{"client error is final", []int{404}, 2, 404, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &upstream{statuses: tt.statuses}
+			srv := httptest.NewServer(u)
+			defer srv.Close()
+
+			c := newClient(t, "retry", settings.OutboundConfig{MaxRetries: tt.retries})
+			code, err := get(t, c, context.Background(), srv.URL)
+			if err != nil || code != tt.want {
+				t.Fatalf("got %d %v, want %d", code, err, tt.want)
+			}
+			if calls := u.calls.Load(); calls != tt.calls {
+				t.Errorf("%d calls, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	u := &upstream{statuses: []int{429}, retryAfter: "1"}
+	srv := httptest.NewServer(u)
+	defer srv.Close()
+
+	// Retry-After asks for 1s, of which at least half is waited
+	c := newClient(t, "retry_after", settings.OutboundConfig{MaxRetries: 1, MaxRetryBackoff: settings.Duration(2 * time.Second)})
+	start := time.Now()
+	if code, err := get(t, c, context.Background(), srv.URL); err != nil || code != 200 {
+		t.Fatalf("got %d %v, want 200", code, err)
+	}
+	if waited := time.Since(start); waited < 500*time.Millisecond {
+		t.Errorf("retried after %s, want Retry-After to be honoured", waited)
+	}
+
+	// but never for longer than the max retry backoff
+	u = &upstream{statuses: []int{429}, retryAfter: "60"}
+	srv2 := httptest.NewServer(u)
+	defer srv2.Close()
+	c = newClient(t, "retry_after_capped", settings.OutboundConfig{MaxRetries: 1})
+	start = time.Now()
+	if code, err := get(t, c, context.Background(), srv2.URL); err != nil || code != 200 {
+		t.Fatalf("got %d %v, want 200", code, err)
+	}
+	if waited := time.Since(start); waited > time.Second {
+		t.Errorf("retried after %s, want at most the max retry backoff", waited)
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	u := &upstream{statuses: []int{500, 500, 500}}
+	srv := httptest.NewServer(u)
+	defer srv.Close()
+
+	c := newClient(t, "breaker", settings.OutboundConfig{
+		BreakerThreshold: 2,
+		BreakerCooldown:  settings.Duration(50 * time.Millisecond),
+	})
+	ctx := context.Background()
+
+	// two failed calls open the breaker
+	for i := 0; i < 2; i++ {
+		if code, _ := get(t, c, ctx, srv.URL); code != 500 {
+			t.Fatalf("call %d: status %d, want 500", i, code)
+		}
+	}
+	if state := c.breaker.current(); state != open {
+		t.Fatalf("breaker %s after 2 failures, want open", state)
+	}
+
+	// which fails calls without sending them
+	if _, err := get(t, c, ctx, srv.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err %v, want ErrCircuitOpen", err)
+	}
+	if calls := u.calls.Load(); calls != 2 {
+		t.Fatalf("%d calls reached the API, want 2", calls)
+	}
+
+	// after the cooldown a failed trial opens it again
+	time.Sleep(60 * time.Millisecond)
+	if code, _ := get(t, c, ctx, srv.URL); code != 500 {
+		t.Fatalf("trial: status %d, want 500", code)
+	}
+	if state := c.breaker.current(); state != open {
+		t.Fatalf("breaker %s after a failed trial, want open", state)
+	}
+
+	// and a successful one closes it
+	time.Sleep(60 * time.Millisecond)
+	if code, err := get(t, c, ctx, srv.URL); err != nil || code != 200 {
+		t.Fatalf("trial: %d %v, want 200", code, err)
+	}
+	if state := c.breaker.current(); state != closed {
+		t.Errorf("breaker %s after a successful trial, want closed", state)
+	}
+}
+
+func TestHalfOpenLetsOneTrialThrough(t *testing.T) {
+	b := newBreaker("half_open", 1, time.Millisecond)
+	b.failure()
+	time.Sleep(2 * time.Millisecond)
+
+	if !b.allow() {
+		t.Fatal("no trial allowed after the cooldown")
+	}
+	if b.allow() {
+		t.Fatal("a second call allowed while the trial is in flight")
+	}
+	b.release()
+	if !b.allow() {
+		t.Fatal("no trial allowed after the first one was released")
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	c := newClient(t, "timeout", settings.OutboundConfig{MaxRetries: 1})
+	c.timeout = 20 * time.Millisecond
+	start := time.Now()
+	if _, err := get(t, c, context.Background(), srv.URL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err %v, want a deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("took %s, want every attempt cut at the timeout", elapsed)
+	}
+	if got := c.metrics.failures.Value(); got != 2 {
+		t.Errorf("%d failures counted, want 2", got)
+	}
+}
+
+func TestCallerCancelIsNotAFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	c := newClient(t, "cancel", settings.OutboundConfig{MaxRetries: 2, BreakerThreshold: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := get(t, c, ctx, srv.URL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err %v, want a deadline exceeded", err)
+	}
+	if got := c.metrics.failures.Value(); got != 0 {
+		t.Errorf("%d failures counted, want 0", got)
+	}
+	if got := c.breaker.current(); got != closed {
+		t.Errorf("breaker %s, want %s", got, closed)
+	}
+}
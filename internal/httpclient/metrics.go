@@ -0,0 +1,72 @@
+package httpclient
+
+import (
+	"expvar"
+	"time"
+)
+
+// outbound holds the metrics of every Client by name, served with the other
+// expvar variables as "outbound_http"
+var outbound = expvar.NewMap("outbound_http")
+
+// upper bounds of the latency buckets, an attempt is counted in the first
+// bucket it fits in or else in "slower"
+var latencyBuckets = []struct {
+	name  string
+	bound time.Duration
+}{
+	{"under_100ms", 100 * time.Millisecond},
+	{"under_250ms", 250 * time.Millisecond},
+	{"under_500ms", 500 * time.Millisecond},
+	{"under_1s", time.Second},
+	{"under_2500ms", 2500 * time.Millisecond},
+}
+
+// metrics of one Client
+type metrics struct {
+	requests      *expvar.Int // attempts sent to the API
+	failures      *expvar.Int // attempts that failed with 429, 5xx or a network error
+	retries       *expvar.Int
+	shortCircuits *expvar.Int // calls failed by the open circuit breaker
+	latencyTotal  *expvar.Int // in milliseconds, over every attempt
+	latency       *expvar.Map // attempts per latency bucket
+}
+
+func newMetrics(name string, b *breaker) *metrics {
+	m := &metrics{
+		requests:      new(expvar.Int),
+		failures:      new(expvar.Int),
+		retries:       new(expvar.Int),
+		shortCircuits: new(expvar.Int),
+		latencyTotal:  new(expvar.Int),
+		latency:       new(expvar.Map).Init(),
+	}
+
+	vars := new(expvar.Map).Init()
+	vars.Set("requests", m.requests)
+	vars.Set("failures", m.failures)
+	vars.Set("retries", m.retries)
+	vars.Set("short_circuits", m.shortCircuits)
+	vars.Set("latency_ms_total", m.latencyTotal)
+	vars.Set("latency", m.latency)
+	vars.Set("breaker", expvar.Func(func() any { return b.current() }))
+	outbound.Set(name, vars)
+	return m
+}
+
+func (m *metrics) observe(latency time.Duration, failed bool) {
+	m.requests.Add(1)
+	if failed {
+		m.failures.Add(1)
+	}
+	m.latencyTotal.Add(latency.Milliseconds())
+
+	bucket := "slower"
+	for _, b := range latencyBuckets {
+		if latency < b.bound {
+			bucket = b.name
+			break
+		}
+	}
+	m.latency.Add(bucket, 1)
+}
@@ -1,40 +1,59 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
+
+	"w3/gc3/internal/apperror"
+	"w3/gc3/internal/auth"
+	"w3/gc3/internal/repository"
+	"w3/gc3/internal/token"
 )
 
-// Define the JWT secret key
-var jwtSecret = []byte("12345")
+// JWTMiddleware rejects requests without a valid token signed by one of the
+// active keys, as well as tokens that have been revoked by a logout. The
+// authenticated user is then available to handlers through auth.CurrentUser.
+func JWTMiddleware(tokens *token.Service, sessions repository.SessionRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authHeader := c.Request().Header.Get("Authorization")
+			if authHeader == "" {
+				return apperror.Unauthorized("missing token")
+			}
 
-func JWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		authHeader := c.Request().Header.Get("Authorization")
-		if authHeader == "" {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Missing token"})
-		}
+			// Extract token from "Bearer <token>"
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				return apperror.Unauthorized("invalid token format")
+			}
+			tokenString := parts[1]
 
-		// Extract token from "Bearer <token>"
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Invalid token format"})
-		}
-		tokenString := parts[1]
-
-		// Parse the token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
-		if err != nil || !token.Valid {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Invalid token"})
-		}
+			// Parse the token
+			claims, err := tokens.Parse(tokenString)
+			if err != nil {
+				return apperror.Unauthorized("invalid token")
+			}
+
+			// Check the token has not been logged out
+			revoked, err := sessions.IsRevoked(c.Request().Context(), claims.ID, claims.UserID, claims.Version)
+			if err != nil {
+				return apperror.Internal(err, "failed to verify token")
+			}
+			if revoked {
+				return apperror.Unauthorized("token has been revoked")
+			}
 
-		// Attach token to context
-		c.Set("user", token)
-		return next(c)
+			// Attach the authenticated user to context
+			auth.SetPrincipal(c, &auth.Principal{
+				UserID:    claims.UserID,
+				Username:  claims.Username,
+				Roles:     claims.Roles,
+				TokenID:   claims.ID,
+				SessionID: claims.SessionID,
+				ExpiresAt: claims.ExpiresAt.Time,
+			})
+			return next(c)
+		}
 	}
 }
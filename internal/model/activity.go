@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// Activity represents an entry of the user activity logs
+type Activity struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"user_id"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+// Comment is a reply written by a user on a post
+type Comment struct {
+	ID         int            `json:"id"`
+	Content    string         `json:"content" validate:"required,notblank"`
+	PostID     int            `json:"post_id" validate:"required"`
+	ParentID   *int           `json:"parent_id"` // comment replied to, nil for top-level comments
+	AuthorID   int            `json:"author_id"`
+	Depth      int            `json:"depth"`   // 0 for top-level comments
+	Version    int            `json:"version"` // 1 until the comment is edited
+	LikeCount  int            `json:"like_count"`
+	ReplyCount int            `json:"reply_count"`
+	Reactions  ReactionCounts `json:"reactions"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Edited     bool           `json:"edited"`
+	EditedAt   *time.Time     `json:"edited_at"`
+}
+
+// CommentRevision is a previous version of an edited comment
+type CommentRevision struct {
+	ID         int       `json:"id"`
+	CommentID  int       `json:"comment_id"`
+	Version    int       `json:"version"` // 1 for the original comment
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"created_at"`  // when this version was published
+	ReplacedAt time.Time `json:"replaced_at"` // when it was replaced by an edit
+	ReplacedBy *int      `json:"replaced_by"` // author or moderator who edited it, nil once deleted
+}
+
+// CommentAuthor is the public part of the user who wrote a comment
+type CommentAuthor struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// PostComment is a comment as listed under its post or its parent comment
+type PostComment struct {
+	ID         int            `json:"id"`
+	Content    string         `json:"content"`
+	ParentID   *int           `json:"parent_id"`
+	Depth      int            `json:"depth"`
+	Author     CommentAuthor  `json:"author"`
+	LikeCount  int            `json:"like_count"`
+	ReplyCount int            `json:"reply_count"`
+	Reactions  ReactionCounts `json:"reactions"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Edited     bool           `json:"edited"`
+	EditedAt   *time.Time     `json:"edited_at"`
+}
+
+// CommentDetail is a comment together with its post and author
+type CommentDetail struct {
+	Comment    Comment
+	PostTitle  string
+	AuthorName string
+}
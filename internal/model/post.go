@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Post is a piece of content published by a user
+type Post struct {
+	ID        int        `json:"id"`
+	Content   string     `json:"content"`
+	ImageURL  string     `json:"image_url" validate:"required,url"`
+	UserID    int        `json:"user_id"`
+	Version   int        `json:"version"` // 1 until the post is edited
+	LikeCount int        `json:"like_count"`
+	LikedByMe bool       `json:"liked_by_me"` // whether the requesting user likes the post
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at"`
+}
+
+// PostRevision is a previous version of an edited post
+type PostRevision struct {
+	ID         int       `json:"id"`
+	PostID     int       `json:"post_id"`
+	Version    int       `json:"version"` // 1 for the original post
+	Content    string    `json:"content"`
+	ImageURL   string    `json:"image_url"`
+	CreatedAt  time.Time `json:"created_at"`  // when this version was published
+	ReplacedAt time.Time `json:"replaced_at"` // when it was replaced by an edit
+}
+
+// PostLiker is a user who liked a post
+type PostLiker struct {
+	UserID   int       `json:"user_id"`
+	Username string    `json:"username"`
+	FullName string    `json:"full_name"`
+	LikedAt  time.Time `json:"liked_at"`
+}
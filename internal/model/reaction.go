@@ -0,0 +1,33 @@
+package model
+
+import "sort"
+
+// Reactions is the fixed set of emoji users can react to comments with, by name
+var Reactions = map[string]string{
+	"thumbs_up": "👍",
+	"heart":     "❤️",
+	"laugh":     "😂",
+	"wow":       "😮",
+	"sad":       "😢",
+	"angry":     "😠",
+}
+
+// ValidReaction reports whether name is one of Reactions
+func ValidReaction(name string) bool {
+	_, ok := Reactions[name]
+	return ok
+}
+
+// ReactionCounts is the number of users who reacted with each reaction, by
+// name; reactions nobody used are left out
+type ReactionCounts map[string]int
+
+// ReactionNames returns the names of Reactions in alphabetical order
+func ReactionNames() []string {
+	names := make([]string, 0, len(Reactions))
+	for name := range Reactions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
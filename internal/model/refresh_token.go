@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// RefreshToken is a stored refresh token, identified by the hash of its value
+type RefreshToken struct {
+	ID        int
+	UserID    int
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time // set once the token has been rotated
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// User is a registered account
+type User struct {
+	ID        int       `json:"id"`         // Primary key, auto-incremented
+	FullName  string    `json:"full_name"`  // Full name of the user
+	Email     string    `json:"email"`      // Email address, unique
+	Username  string    `json:"username"`   // Username, unique
+	Password  string    `json:"-"`          // bcrypt hash, never serialized
+	Age       int       `json:"age"`        // Age of the user
+	Bio       string    `json:"bio"`        // Short self-description
+	AvatarURL string    `json:"avatar_url"` // Profile picture, empty when not set
+	Role      string    `json:"role"`       // user, moderator or admin
+	CreatedAt time.Time `json:"created_at"` // Date and time of registration
+	// When the user deleted their account, nil unless it is waiting to be purged
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	FollowerCount  int `json:"follower_count"`  // Number of users following this user
+	FollowingCount int `json:"following_count"` // Number of users this user follows
+}
+
+// UserProfile is the public view of a user, without their email, age or
+// password
+type UserProfile struct {
+	ID             int       `json:"id"`
+	Username       string    `json:"username"`
+	FullName       string    `json:"full_name"`
+	Bio            string    `json:"bio"`
+	AvatarURL      string    `json:"avatar_url"`
+	PostCount      int       `json:"post_count"`
+	FollowerCount  int       `json:"follower_count"`
+	FollowingCount int       `json:"following_count"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Follow is the other user of a follow relationship in a followers or
+// following listing
+type Follow struct {
+	UserID     int       `json:"user_id"`
+	Username   string    `json:"username"`
+	FullName   string    `json:"full_name"`
+	FollowedAt time.Time `json:"followed_at"`
+}
@@ -0,0 +1,93 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultLimit is the page size used when the client does not ask for one
+	DefaultLimit = 20
+	// MaxLimit caps the page size a client may ask for
+	MaxLimit = 100
+)
+
+var (
+	// ErrInvalidCursor is returned for cursors that were not issued by Encode,
+	// or that were issued for another ordering
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidLimit is returned for limits that are not between 1 and MaxLimit
+	ErrInvalidLimit = fmt.Errorf("limit must be between 1 and %d", MaxLimit)
+)
+
+// Cursor is the position of the last item of a page in a keyset ordering:
+// the sort key of that item (Time or Count, depending on the ordering) and
+// its id as the tie-breaker. Clients only ever see it encoded.
+type Cursor struct {
+	Order string    `json:"o"`
+	Time  time.Time `json:"t"`
+	Count int       `json:"c,omitempty"`
+	ID    int       `json:"i"`
+}
+
+// Params is a parsed page request
+type Params struct {
+	Limit int
+	After *Cursor // nil for the first page
+}
+
+// Parse reads the raw `limit` and `cursor` query parameters of a listing sorted
+// by order. Empty values mean the first page of DefaultLimit items.
+func Parse(limit, cursor, order string) (Params, error) {
+	params := Params{Limit: DefaultLimit}
+
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > MaxLimit {
+			return params, ErrInvalidLimit
+		}
+		params.Limit = n
+	}
+
+	if cursor != "" {
+		after, err := Decode(cursor)
+		if err != nil || after.Order != order {
+			return params, ErrInvalidCursor
+		}
+		params.After = after
+	}
+	return params, nil
+}
+
+// Encode returns the opaque form of c handed out as `next_cursor`
+func Encode(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode parses a cursor produced by Encode
+func Decode(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Page trims items, fetched with a limit of params.Limit+1, to one page and
+// returns the cursor of the next page, empty when this is the last one
+func Page[T any](items []T, params Params, cursorOf func(T) Cursor) ([]T, string) {
+	if len(items) <= params.Limit {
+		return items, ""
+	}
+	items = items[:params.Limit]
+	return items, Encode(cursorOf(items[len(items)-1]))
+}
@@ -0,0 +1,91 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	want := Cursor{Order: "newest", Time: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), Count: 7, ID: 42}
+	got, err := Decode(Encode(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Order != want.Order || !got.Time.Equal(want.Time) || got.Count != want.Count || got.ID != want.ID {
+		t.Errorf("decoded %+v, want %+v", *got, want)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, cursor := range []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("not json")),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"o":"newest","i":0}`)),
+	} {
+		if _, err := Decode(cursor); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Decode(%q) = %v, want ErrInvalidCursor", cursor, err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	newest := Encode(Cursor{Order: "newest", ID: 3})
+
+	tests := []struct {
+		name   string
+		limit  string
+		cursor string
+		want   Params
+		err    error
+	}{
+		{"defaults", "", "", Params{Limit: DefaultLimit}, nil},
+		{"limit", "5", "", Params{Limit: 5}, nil},
+		{"max limit", "100", "", Params{Limit: MaxLimit}, nil},
+		{"zero limit", "0", "", Params{}, ErrInvalidLimit},
+		{"too large limit", "101", "", Params{}, ErrInvalidLimit},
+		{"not a number", "ten", "", Params{}, ErrInvalidLimit},
+		{"cursor", "", newest, Params{Limit: DefaultLimit, After: &Cursor{Order: "newest", ID: 3}}, nil},
+		{"cursor of another order", "", Encode(Cursor{Order: "oldest", ID: 3}), Params{}, ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.limit, tt.cursor, "newest")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if got.Limit != tt.want.Limit || (got.After == nil) != (tt.want.After == nil) {
+				t.Fatalf("params %+v, want %+v", got, tt.want)
+			}
+			if got.After != nil && got.After.ID != tt.want.After.ID {
+				t.Errorf("cursor id %d, want %d", got.After.ID, tt.want.After.ID)
+			}
+		})
+	}
+}
+
+func TestPage(t *testing.T) {
+	params := Params{Limit: 2}
+	cursorOf := func(id int) Cursor { return Cursor{Order: "newest", ID: id} }
+
+	// a repository asked for Limit+1 rows returns the extra one when there is a next page
+	items, next := Page([]int{5, 4, 3}, params, cursorOf)
+	if len(items) != 2 || items[1] != 4 {
+		t.Fatalf("items %v, want [5 4]", items)
+	}
+	after, err := Decode(next)
+	if err != nil || after.ID != 4 {
+		t.Errorf("next cursor %+v (%v), want the one of item 4", after, err)
+	}
+
+	for _, last := range [][]int{{2, 1}, {1}, {}} {
+		items, next := Page(last, params, cursorOf)
+		if len(items) != len(last) || next != "" {
+			t.Errorf("Page(%v) = %v, %q, want every item and no next cursor", last, items, next)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"w3/gc3/internal/apperror"
+	"w3/gc3/internal/auth"
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/pagination"
+	"w3/gc3/internal/repository"
+)
+
+// @Summary Get the home feed
+// @Description Retrieve a page of your own posts and the posts of the users you follow, newest first. Pass the returned next_cursor as cursor to get the following page; it is empty on the last page.
+// @Tags Posts
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} PostListResponse "Page of posts"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /feed [get]
+func (h *PostHandler) GetFeed(c echo.Context) error {
+	principal, ok := auth.CurrentUser(c)
+	if !ok {
+		return apperror.Unauthorized("not authorized")
+	}
+
+	page, err := pagination.Parse(c.QueryParam("limit"), c.QueryParam("cursor"), repository.OrderNewest)
+	if err != nil {
+		return apperror.BadRequest(err.Error())
+	}
+
+	// one extra post tells whether there is a next page
+	posts, err := h.posts.Feed(c.Request().Context(), repository.FeedFilter{
+		UserID: principal.UserID,
+		Limit:  page.Limit + 1,
+		After:  page.After,
+	})
+	if err != nil {
+		return apperror.Internal(err, "failed to fetch feed")
+	}
+
+	posts, next := pagination.Page(posts, page, repository.PostCursor(repository.OrderNewest))
+	refs := make([]*model.Post, len(posts))
+	for i := range posts {
+		refs[i] = &posts[i]
+	}
+	if err := h.markLiked(c, refs...); err != nil {
+		return apperror.Internal(err, "failed to fetch likes")
+	}
+	return c.JSON(http.StatusOK, PostListResponse{Posts: posts, NextCursor: next})
+}
@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"w3/gc3/internal/handlertest"
+	"w3/gc3/internal/repository/memory"
+)
+
+func TestGetFeed(t *testing.T) {
+	store := memory.NewStore()
+	alice := handlertest.CreateUser(t, store, "alice")
+	bob := handlertest.CreateUser(t, store, "bob")
+	carol := handlertest.CreateUser(t, store, "carol")
+	if _, err := store.Repositories().Users.Follow(context.Background(), alice.ID, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// posts 1 to 6 alternate between alice, bob and carol
+	for i := 0; i < 2; i++ {
+		for _, user := range []int{alice.ID, bob.ID, carol.ID} {
+			createPost(t, newServer(store, user), `{"content":"post","image_url":"https://example.com/a.png"}`)
+		}
+	}
+
+	e := newServer(store, alice.ID)
+	var ids []float64
+	path := "/feed?limit=3"
+	for pages := 0; ; pages++ {
+		if pages == 3 {
+			t.Fatal("too many pages")
+		}
+		resp := handlertest.Do(t, e, "GET", path, "")
+		if resp.Code != 200 {
+			t.Fatalf("status %d: %v", resp.Code, resp.Body)
+		}
+		for _, p := range resp.Body["posts"].([]any) {
+			ids = append(ids, p.(map[string]any)["id"].(float64))
+		}
+		next := resp.Body["next_cursor"].(string)
+		if next == "" {
+			break
+		}
+		path = "/feed?limit=3&cursor=" + next
+	}
+	// alice's own posts and bob's, newest first, none of carol's
+	want := []float64{5, 4, 2, 1}
+	if len(ids) != len(want) {
+		t.Fatalf("ids %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("ids %v, want %v", ids, want)
+		}
+	}
+
+	if resp := handlertest.Do(t, e, "GET", "/feed?cursor=garbage", ""); resp.Code != 400 {
+		t.Errorf("bad cursor: status %d, want 400", resp.Code)
+	}
+}
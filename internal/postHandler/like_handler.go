@@ -0,0 +1,163 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"w3/gc3/internal/apperror"
+	"w3/gc3/internal/auth"
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/pagination"
+	"w3/gc3/internal/repository"
+)
+
+// LikerListResponse is one page of the users who liked a post
+type LikerListResponse struct {
+	Likers     []model.PostLiker `json:"likers"`
+	NextCursor string            `json:"next_cursor"` // empty on the last page
+}
+
+// @Summary Like a post
+// @Description Like a post, liking it again has no effect
+// @Tags Posts
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Success 200 {object} map[string]interface{} "Post liked"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "Post not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /posts/{id}/like [post]
+func (h *PostHandler) LikePost(c echo.Context) error {
+	return h.setLike(c, true)
+}
+
+// @Summary Unlike a post
+// @Description Remove your like from a post, unliking it again has no effect
+// @Tags Posts
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Success 200 {object} map[string]interface{} "Post unliked"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "Post not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /posts/{id}/like [delete]
+func (h *PostHandler) UnlikePost(c echo.Context) error {
+	return h.setLike(c, false)
+}
+
+// @Summary Get the likes of a post
+// @Description Retrieve a page of the users who liked a post, latest like first. Pass the returned next_cursor as cursor to get the following page; it is empty on the last page.
+// @Tags Posts
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} LikerListResponse "Page of likers"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "Post not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /posts/{id}/likes [get]
+func (h *PostHandler) GetPostLikes(c echo.Context) error {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("invalid post ID")
+	}
+
+	page, err := pagination.Parse(c.QueryParam("limit"), c.QueryParam("cursor"), repository.OrderNewest)
+	if err != nil {
+		return apperror.BadRequest(err.Error())
+	}
+
+	if _, err := h.posts.GetActiveByID(c.Request().Context(), postID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("post not found")
+		}
+		return apperror.Internal(err, "failed to fetch post")
+	}
+
+	// one extra liker tells whether there is a next page
+	likers, err := h.posts.ListLikers(c.Request().Context(), repository.LikeFilter{
+		PostID: postID,
+		Limit:  page.Limit + 1,
+		After:  page.After,
+	})
+	if err != nil {
+		return apperror.Internal(err, "failed to fetch likes")
+	}
+
+	likers, next := pagination.Page(likers, page, repository.LikerCursor)
+	return c.JSON(http.StatusOK, LikerListResponse{Likers: likers, NextCursor: next})
+}
+
+// setLike adds or removes the principal's like and logs it when something changed
+func (h *PostHandler) setLike(c echo.Context, like bool) error {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("invalid post ID")
+	}
+
+	principal, ok := auth.CurrentUser(c)
+	if !ok {
+		return apperror.Unauthorized("not authorized")
+	}
+	userID := principal.UserID
+
+	var post *model.Post
+	ctx := c.Request().Context()
+	load := func(repos repository.Repositories) (err error) {
+		post, err = repos.Posts.GetByID(ctx, postID)
+		return err
+	}
+	description := "User liked POST with ID " + strconv.Itoa(postID)
+	toggle := func(repos repository.Repositories) (bool, error) {
+		return repos.Posts.Like(ctx, postID, userID)
+	}
+	if !like {
+		description = "User unliked POST with ID " + strconv.Itoa(postID)
+		toggle = func(repos repository.Repositories) (bool, error) {
+			return repos.Posts.Unlike(ctx, postID, userID)
+		}
+	}
+	err = repository.Toggle(ctx, h.uow, userID, description, load, toggle)
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound("post not found")
+	}
+	if err != nil {
+		return apperror.Internal(err, "failed to update like")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"liked":      like,
+		"like_count": post.LikeCount,
+	})
+}
+
+// markLiked sets LikedByMe on posts for the requesting user
+func (h *PostHandler) markLiked(c echo.Context, posts ...*model.Post) error {
+	principal, ok := auth.CurrentUser(c)
+	if !ok || len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	liked, err := h.posts.LikedBy(c.Request().Context(), principal.UserID, ids)
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		post.LikedByMe = liked[post.ID]
+	}
+	return nil
+}
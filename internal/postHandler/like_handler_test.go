@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"w3/gc3/internal/handlertest"
+	"w3/gc3/internal/repository/memory"
+)
+
+func TestLikePost(t *testing.T) {
+	store := memory.NewStore()
+	alice := handlertest.CreateUser(t, store, "alice")
+	e := newServer(store, alice.ID)
+	path := "/posts/" + strconv.Itoa(createPost(t, e, `{"content":"post","image_url":"https://example.com/a.png"}`)) + "/like"
+
+	steps := []struct {
+		method string
+		liked  bool
+		count  float64
+	}{
+		{"POST", true, 1},
+		{"POST", true, 1}, // liking again has no effect
+		{"DELETE", false, 0},
+		{"DELETE", false, 0},
+	}
+	for _, step := range steps {
+		resp := handlertest.Do(t, e, step.method, path, "")
+		if resp.Code != 200 || resp.Body["liked"] != step.liked || resp.Body["like_count"] != step.count {
+			t.Fatalf("%s %s: status %d: %v", step.method, path, resp.Code, resp.Body)
+		}
+	}
+
+	// only the like and the unlike that changed something are logged,
+	// next to the post creation
+	activities, err := store.Repositories().Activities.ListByUser(context.Background(), alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(activities) != 3 {
+		t.Errorf("%d activities, want 3: %v", len(activities), activities)
+	}
+
+	if resp := handlertest.Do(t, e, "POST", "/posts/99/like", ""); resp.Code != 404 {
+		t.Errorf("like missing post: status %d, want 404", resp.Code)
+	}
+}
@@ -1,86 +1,108 @@
 package internal
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 	"strings"
-	"log"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 
-	"w3/gc3/config/database"
-	"w3/gc3/utils"
+	"w3/gc3/internal/apperror"
+	"w3/gc3/internal/auth"
+	"w3/gc3/internal/content"
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/pagination"
+	"w3/gc3/internal/repository"
 	"strconv"
-
-	activity_handler "w3/gc3/internal/activityHandler"
 )
 
-// Validator instance
-var validate = validator.New()
+// UpdatePostRequest struct, omitted fields are left unchanged by PATCH and required by PUT.
+// Every post has an image, so an empty image_url is rejected rather than removing it.
+type UpdatePostRequest struct {
+	Content  *string `json:"content"`
+	ImageURL *string `json:"image_url" validate:"omitempty,url"`
+}
+
+// PostListResponse is one page of posts
+type PostListResponse struct {
+	Posts      []model.Post `json:"posts"`
+	NextCursor string       `json:"next_cursor"` // empty on the last page
+}
+
+// CommentListResponse is one page of the comments of a post
+type CommentListResponse struct {
+	Comments   []model.PostComment `json:"comments"`
+	NextCursor string              `json:"next_cursor"` // empty on the last page
+}
+
+// number of comments embedded in GetPostByID
+const commentPreviewSize = 3
 
-// Post struct
-type Post struct {
-	ID       int    `json:"id"`
-	Content  string `json:"content"`
-	ImageURL string `json:"image_url" validate:"required,url"`
-	UserID   int    `json:"user_id"`
+// returned from inside a unit of work when the principal may not edit the post
+var errForbidden = errors.New("forbidden")
+
+// PostHandler serves the /posts endpoints
+type PostHandler struct {
+	posts    repository.PostRepository
+	comments repository.CommentRepository
+	uow      repository.UnitOfWork
+	content  content.ContentProvider // fills in posts created without content
+}
+
+func NewPostHandler(posts repository.PostRepository, comments repository.CommentRepository, uow repository.UnitOfWork, provider content.ContentProvider) *PostHandler {
+	return &PostHandler{posts: posts, comments: comments, uow: uow, content: provider}
 }
 
 // @Summary Create a new post
-// @Description Add a new post with optional image URL and content
+// @Description Add a new post with an image URL and optional content, a random joke is posted when the content is empty
 // @Tags Posts
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
-// @Param request body Post true "Post data"
+// @Param request body model.Post true "Post data"
 // @Success 201 {object} map[string]interface{} "Post created successfully"
-// @Failure 400 {object} map[string]string "Invalid input"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 500 {object} apperror.Response "Internal server error"
 // @Router /posts [post]
-func CreatePost(c echo.Context) error {
-	userID, _ := utils.GetUserIDFromToken(c)
-	if userID == 0 {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"message": "not authorized"})
+func (h *PostHandler) CreatePost(c echo.Context) error {
+	principal, ok := auth.CurrentUser(c)
+	if !ok {
+		return apperror.Unauthorized("not authorized")
 	}
+	userID := principal.UserID
 
-	post := new(Post)
+	post := new(model.Post)
 	if err := c.Bind(post); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "invalid request body"})
+		return apperror.BadRequest("invalid request body")
 	}
 
 	// Validate ImageURL format
-	if err := validate.Struct(post); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "invalid image_url format"})
+	if err := c.Validate(post); err != nil {
+		return err
 	}
 
-	// If content is missing, fetch random joke
+	// If content is missing, post a random joke instead
 	if strings.TrimSpace(post.Content) == "" {
-		joke, err := utils.FetchRandomJoke()
-		fmt.Printf("joke: %s\n", joke)
-		fmt.Println(err)
-
+		joke, err := h.content.Random(c.Request().Context())
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to fetch random joke"})
+			return apperror.Internal(err, "failed to fetch random joke")
 		}
 		post.Content = joke
 	}
 
 	post.UserID = userID
 
-	
-	// Insert post into the database
-	query := `INSERT INTO posts (content, image_url, user_id) VALUES ($1, $2, $3) RETURNING id`
-	err := config.Pool.QueryRow(c.Request().Context(), query, post.Content, post.ImageURL, post.UserID).Scan(&post.ID)
+	// Insert post and log the activity in one transaction
+	err := h.uow.Do(c.Request().Context(), func(repos repository.Repositories) error {
+		if err := repos.Posts.Create(c.Request().Context(), post); err != nil {
+			return err
+		}
+		description := "User created a new POST with ID " + strconv.Itoa(post.ID)
+		return repos.Activities.Log(c.Request().Context(), userID, description)
+	})
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to create post"})
-	}
-	
-	// log post activity
-	description := "User created a new POST with ID " + strconv.Itoa(post.ID)
-	if err := activity_handler.LogActivity(c, userID, description); err != nil {
-		log.Printf("Failed to log activity: %v", err)
+		return apperror.Internal(err, "failed to create post")
 	}
 
 	return c.JSON(http.StatusCreated, map[string]interface{}{
@@ -90,32 +112,59 @@ func CreatePost(c echo.Context) error {
 }
 
 // @Summary Get all posts
-// @Description Retrieve a list of all posts
+// @Description Retrieve a page of posts, newest first by default. Pass the returned next_cursor as cursor to get the following page; it is empty on the last page.
 // @Tags Posts
 // @Produce json
 // @Param Authorization header string true "Bearer token"
-// @Success 200 {array} Post "List of posts"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param order query string false "newest (default) or oldest"
+// @Param user_id query int false "Only the posts of this user"
+// @Success 200 {object} PostListResponse "Page of posts"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 500 {object} apperror.Response "Internal server error"
 // @Router /posts [get]
-func GetAllPosts(c echo.Context) error {
-	query := `SELECT id, content, image_url, user_id FROM posts`
-	rows, err := config.Pool.Query(c.Request().Context(), query)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to fetch posts"})
+func (h *PostHandler) GetAllPosts(c echo.Context) error {
+	order := c.QueryParam("order")
+	if order == "" {
+		order = repository.OrderNewest
+	}
+	if order != repository.OrderNewest && order != repository.OrderOldest {
+		return apperror.BadRequest("order must be newest or oldest")
 	}
-	defer rows.Close()
 
-	posts := []Post{}
-	for rows.Next() {
-		var post Post
-		if err := rows.Scan(&post.ID, &post.Content, &post.ImageURL, &post.UserID); err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to scan posts"})
+	filter := repository.PostFilter{Order: order}
+	if v := c.QueryParam("user_id"); v != "" {
+		userID, err := strconv.Atoi(v)
+		if err != nil || userID <= 0 {
+			return apperror.BadRequest("invalid user ID")
 		}
-		posts = append(posts, post)
+		filter.UserID = userID
 	}
 
-	return c.JSON(http.StatusOK, posts)
+	page, err := pagination.Parse(c.QueryParam("limit"), c.QueryParam("cursor"), order)
+	if err != nil {
+		return apperror.BadRequest(err.Error())
+	}
+	// one extra post tells whether there is a next page
+	filter.Limit = page.Limit + 1
+	filter.After = page.After
+
+	posts, err := h.posts.List(c.Request().Context(), filter)
+	if err != nil {
+		return apperror.Internal(err, "failed to fetch posts")
+	}
+
+	posts, next := pagination.Page(posts, page, repository.PostCursor(order))
+	refs := make([]*model.Post, len(posts))
+	for i := range posts {
+		refs[i] = &posts[i]
+	}
+	if err := h.markLiked(c, refs...); err != nil {
+		return apperror.Internal(err, "failed to fetch likes")
+	}
+	return c.JSON(http.StatusOK, PostListResponse{Posts: posts, NextCursor: next})
 }
 
 // @Summary Get post details by ID
@@ -125,58 +174,239 @@ func GetAllPosts(c echo.Context) error {
 // @Param Authorization header string true "Bearer token"
 // @Param id path int true "Post ID"
 // @Success 200 {object} map[string]interface{} "Post details"
-// @Failure 400 {object} map[string]string "Invalid input"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 404 {object} map[string]string "Post not found"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "Post not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
 // @Router /posts/{id} [get]
-func GetPostByID(c echo.Context) error {
+func (h *PostHandler) GetPostByID(c echo.Context) error {
 	postID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "invalid post ID"})
+		return apperror.BadRequest("invalid post ID")
+	}
+
+	post, err := h.posts.GetActiveByID(c.Request().Context(), postID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound("post not found")
+	}
+	if err != nil {
+		return apperror.Internal(err, "failed to fetch post")
+	}
+	if err := h.markLiked(c, post); err != nil {
+		return apperror.Internal(err, "failed to fetch likes")
 	}
 
-	var post Post
-	query := `SELECT id, content, image_url, user_id FROM posts WHERE id = $1`
-	err = config.Pool.QueryRow(c.Request().Context(), query, postID).Scan(&post.ID, &post.Content, &post.ImageURL, &post.UserID)
+	// Only the first comments are embedded, the rest is paged through
+	// GET /posts/:id/comments starting at comments_next_cursor
+	preview := pagination.Params{Limit: commentPreviewSize}
+	comments, err := h.comments.List(c.Request().Context(), repository.CommentFilter{
+		PostID: postID,
+		Order:  repository.OrderOldest,
+		Limit:  preview.Limit + 1,
+	})
 	if err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "post not found"})
+		return apperror.Internal(err, "failed to fetch comments")
 	}
+	comments, next := pagination.Page(comments, preview, repository.CommentCursor(repository.OrderOldest))
 
-	// Fetch comments for the post
-	queryComments := `SELECT c.id, c.content, c.author_id, u.full_name 
-                      FROM comments c 
-                      JOIN users u ON c.author_id = u.id 
-                      WHERE c.post_id = $1`
-	rows, err := config.Pool.Query(c.Request().Context(), queryComments, postID)
+	count, err := h.comments.CountByPost(c.Request().Context(), postID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to fetch comments"})
+		return apperror.Internal(err, "failed to count comments")
 	}
-	defer rows.Close()
 
-	comments := []map[string]interface{}{}
-	for rows.Next() {
-		var commentID, authorID int
-		var content, authorName string
-		if err := rows.Scan(&commentID, &content, &authorID, &authorName); err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to scan comments"})
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"post":                 post,
+		"comments":             comments,
+		"comment_count":        count,
+		"comments_next_cursor": next,
+	})
+}
+
+// @Summary Get the comments of a post
+// @Description Retrieve a page of the top-level comments of a post, oldest first by default. Replies are listed by GET /comments/{id}/replies. Pass the returned next_cursor as cursor to get the following page; it is empty on the last page.
+// @Tags Posts
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param order query string false "oldest (default), newest or most_liked"
+// @Success 200 {object} CommentListResponse "Page of comments"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "Post not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /posts/{id}/comments [get]
+func (h *PostHandler) GetPostComments(c echo.Context) error {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("invalid post ID")
+	}
+
+	order := c.QueryParam("order")
+	switch order {
+	case "":
+		order = repository.OrderOldest
+	case repository.OrderOldest, repository.OrderNewest, repository.OrderMostLiked:
+	default:
+		return apperror.BadRequest("order must be oldest, newest or most_liked")
+	}
+
+	page, err := pagination.Parse(c.QueryParam("limit"), c.QueryParam("cursor"), order)
+	if err != nil {
+		return apperror.BadRequest(err.Error())
+	}
+
+	if _, err := h.posts.GetActiveByID(c.Request().Context(), postID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("post not found")
 		}
-		comments = append(comments, map[string]interface{}{
-			"id":      commentID,
-			"content": content,
-			"author": map[string]interface{}{
-				"id":   authorID,
-				"name": authorName,
-			},
-		})
+		return apperror.Internal(err, "failed to fetch post")
+	}
+
+	// one extra comment tells whether there is a next page
+	comments, err := h.comments.List(c.Request().Context(), repository.CommentFilter{
+		PostID: postID,
+		Order:  order,
+		Limit:  page.Limit + 1,
+		After:  page.After,
+	})
+	if err != nil {
+		return apperror.Internal(err, "failed to fetch comments")
+	}
+
+	comments, next := pagination.Page(comments, page, repository.CommentCursor(order))
+	return c.JSON(http.StatusOK, CommentListResponse{Comments: comments, NextCursor: next})
+}
+
+// @Summary Edit a post
+// @Description Change the content and/or image URL of a post (owner only). PATCH updates the given fields, PUT requires both. The image cannot be removed, an empty image_url is rejected like any invalid URL. The replaced version is kept as a revision.
+// @Tags Posts
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Param request body UpdatePostRequest true "New post data"
+// @Success 200 {object} map[string]interface{} "Post updated successfully"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Post not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /posts/{id} [patch]
+// @Router /posts/{id} [put]
+func (h *PostHandler) UpdatePost(c echo.Context) error {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("invalid post ID")
+	}
+
+	principal, ok := auth.CurrentUser(c)
+	if !ok {
+		return apperror.Unauthorized("not authorized")
+	}
+	userID := principal.UserID
+
+	req := new(UpdatePostRequest)
+	if err := c.Bind(req); err != nil {
+		return apperror.BadRequest("invalid request body")
+	}
+
+	// PUT replaces the whole post, PATCH only what is given
+	if c.Request().Method == http.MethodPut && (req.Content == nil || req.ImageURL == nil) {
+		return apperror.BadRequest("content and image_url are required")
+	}
+	if req.Content == nil && req.ImageURL == nil {
+		return apperror.BadRequest("nothing to update")
+	}
+	if req.Content != nil && strings.TrimSpace(*req.Content) == "" {
+		return apperror.BadRequest("content must not be empty")
+	}
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	// Check ownership, save the post and log the activity in one transaction
+	var post *model.Post
+	ctx := c.Request().Context()
+	err = h.uow.Do(ctx, func(repos repository.Repositories) error {
+		current, err := repos.Posts.GetByID(ctx, postID)
+		if err != nil {
+			return err
+		}
+		if !auth.Can(principal, auth.EditPost, current.UserID) {
+			return errForbidden
+		}
+
+		post = current
+		if (req.Content == nil || *req.Content == current.Content) && (req.ImageURL == nil || *req.ImageURL == current.ImageURL) {
+			// nothing changed, no revision to keep
+			return nil
+		}
+		if req.Content != nil {
+			post.Content = *req.Content
+		}
+		if req.ImageURL != nil {
+			post.ImageURL = *req.ImageURL
+		}
+		if err := repos.Posts.Update(ctx, post); err != nil {
+			return err
+		}
+		description := "User edited POST with ID " + strconv.Itoa(postID)
+		return repos.Activities.Log(ctx, userID, description)
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound("post not found")
+	}
+	if errors.Is(err, errForbidden) {
+		return apperror.Forbidden("you are not authorized to edit this post")
+	}
+	if err != nil {
+		return apperror.Internal(err, "failed to update post")
+	}
+	if err := h.markLiked(c, post); err != nil {
+		return apperror.Internal(err, "failed to fetch likes")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"post":     post,
-		"comments": comments,
+		"message": "post updated successfully",
+		"post":    post,
 	})
 }
 
+// @Summary Get the revisions of a post
+// @Description Retrieve the previous versions of an edited post, oldest first
+// @Tags Posts
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Success 200 {array} model.PostRevision "List of revisions"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "Post not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /posts/{id}/revisions [get]
+func (h *PostHandler) GetPostRevisions(c echo.Context) error {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("invalid post ID")
+	}
+
+	if _, err := h.posts.GetActiveByID(c.Request().Context(), postID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("post not found")
+		}
+		return apperror.Internal(err, "failed to fetch post")
+	}
+
+	revisions, err := h.posts.ListRevisions(c.Request().Context(), postID)
+	if err != nil {
+		return apperror.Internal(err, "failed to fetch revisions")
+	}
+
+	return c.JSON(http.StatusOK, revisions)
+}
+
 // @Summary Delete a post by ID
 // @Description Remove a specific post by its ID
 // @Tags Posts
@@ -184,39 +414,50 @@ func GetPostByID(c echo.Context) error {
 // @Param Authorization header string true "Bearer token"
 // @Param id path int true "Post ID"
 // @Success 200 {object} map[string]string "Post deleted successfully"
-// @Failure 400 {object} map[string]string "Invalid input"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 403 {object} map[string]string "Forbidden"
-// @Failure 404 {object} map[string]string "Post not found"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Post not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
 // @Router /posts/{id} [delete]
-func DeletePost(c echo.Context) error {
+func (h *PostHandler) DeletePost(c echo.Context) error {
 	postID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "invalid post ID"})
+		return apperror.BadRequest("invalid post ID")
 	}
 
-	userID, _ := utils.GetUserIDFromToken(c) // Assuming a utility function to extract UserID from JWT token
-	if userID == 0 {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"message": "unauthenticated"})
+	principal, ok := auth.CurrentUser(c)
+	if !ok {
+		return apperror.Unauthorized("not authorized")
 	}
+	userID := principal.UserID
 
-	var postOwnerID int
-	query := `SELECT user_id FROM posts WHERE id = $1`
-	err = config.Pool.QueryRow(c.Request().Context(), query, postID).Scan(&postOwnerID)
+	post, err := h.posts.GetByID(c.Request().Context(), postID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound("post not found")
+	}
 	if err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "post not found"})
+		return apperror.Internal(err, "failed to fetch post")
 	}
 
-	if postOwnerID != userID {
-		return c.JSON(http.StatusForbidden, map[string]string{"message": "you are not authorized to delete this post"})
+	// Only the owner of the post or a moderator may delete it
+	if !auth.Can(principal, auth.DeletePost, post.UserID) {
+		return apperror.Forbidden("you are not authorized to delete this post")
 	}
 
-	// Delete the post
-	queryDelete := `DELETE FROM posts WHERE id = $1`
-	_, err = config.Pool.Exec(c.Request().Context(), queryDelete, postID)
+	// Delete the post and log the activity in one transaction
+	err = h.uow.Do(c.Request().Context(), func(repos repository.Repositories) error {
+		if err := repos.Posts.Delete(c.Request().Context(), postID); err != nil {
+			return err
+		}
+		description := "User deleted POST with ID " + strconv.Itoa(postID)
+		return repos.Activities.Log(c.Request().Context(), userID, description)
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound("post not found")
+	}
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "failed to delete post"})
+		return apperror.Internal(err, "failed to delete post")
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{"message": "post deleted successfully"})
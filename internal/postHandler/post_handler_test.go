@@ -0,0 +1,296 @@
+package internal
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"w3/gc3/internal/auth"
+	"w3/gc3/internal/content"
+	"w3/gc3/internal/handlertest"
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository/memory"
+)
+
+// newServer serves the post routes to the user with userID
+func newServer(store *memory.Store, userID int, roles ...string) *echo.Echo {
+	repos := store.Repositories()
+	h := NewPostHandler(repos.Posts, repos.Comments, store, content.Static("a joke"))
+
+	e := handlertest.NewEcho()
+	as := handlertest.As(userID, roles...)
+	e.POST("/posts", h.CreatePost, as)
+	e.GET("/posts", h.GetAllPosts, as)
+	e.GET("/feed", h.GetFeed, as)
+	e.GET("/posts/:id", h.GetPostByID, as)
+	e.GET("/posts/:id/comments", h.GetPostComments, as)
+	e.GET("/posts/:id/likes", h.GetPostLikes, as)
+	e.GET("/posts/:id/revisions", h.GetPostRevisions, as)
+	e.PATCH("/posts/:id", h.UpdatePost, as)
+	e.PUT("/posts/:id", h.UpdatePost, as)
+	e.DELETE("/posts/:id", h.DeletePost, as)
+	e.POST("/posts/:id/like", h.LikePost, as)
+	e.DELETE("/posts/:id/like", h.UnlikePost, as)
+	return e
+}
+
+func createPost(t *testing.T, e *echo.Echo, body string) int {
+	t.Helper()
+	resp := handlertest.Do(t, e, "POST", "/posts", body)
+	if resp.Code != 201 {
+		t.Fatalf("create post: status %d, body %v", resp.Code, resp.Body)
+	}
+	return int(resp.Body["post"].(map[string]any)["id"].(float64))
+}
+
+func TestCreatePost(t *testing.T) {
+	store := memory.NewStore()
+	alice := handlertest.CreateUser(t, store, "alice")
+	e := newServer(store, alice.ID)
+
+	tests := []struct {
+		name    string
+		body    string
+		code    int
+		content string
+	}{
+		{"with content", `{"content":"hello","image_url":"https://example.com/a.png"}`, 201, "hello"},
+		{"blank content gets some", `{"content":"  ","image_url":"https://example.com/a.png"}`, 201, "a joke"},
+		{"missing image", `{"content":"hello"}`, 400, ""},
+		{"invalid image", `{"content":"hello","image_url":"not a url"}`, 400, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := handlertest.Do(t, e, "POST", "/posts", tt.body)
+			if resp.Code != tt.code {
+				t.Fatalf("status %d, want %d: %v", resp.Code, tt.code, resp.Body)
+			}
+			if tt.code != 201 {
+				return
+			}
+			post := resp.Body["post"].(map[string]any)
+			if post["content"] != tt.content {
+				t.Errorf("content %q, want %q", post["content"], tt.content)
+			}
+			if int(post["user_id"].(float64)) != alice.ID {
+				t.Errorf("user_id %v, want %d", post["user_id"], alice.ID)
+			}
+		})
+	}
+
+	// each post and its activity entry are written together
+	activities, err := store.Repositories().Activities.ListByUser(context.Background(), alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(activities) != 2 {
+		t.Errorf("%d activities, want 2: %v", len(activities), activities)
+	}
+}
+
+func TestUpdatePost(t *testing.T) {
+	store := memory.NewStore()
+	alice := handlertest.CreateUser(t, store, "alice")
+	bob := handlertest.CreateUser(t, store, "bob")
+	mod := handlertest.CreateUser(t, store, "mod")
+	asAlice := newServer(store, alice.ID)
+	id := createPost(t, asAlice, `{"content":"first","image_url":"https://example.com/a.png"}`)
+	path := "/posts/" + strconv.Itoa(id)
+
+	if resp := handlertest.Do(t, newServer(store, bob.ID), "PATCH", path, `{"content":"hijacked"}`); resp.Code != 403 {
+		t.Fatalf("other user: status %d, want 403", resp.Code)
+	}
+	// moderators remove posts, they do not rewrite them
+	asMod := newServer(store, mod.ID, auth.RolesFor(auth.RoleModerator)...)
+	if resp := handlertest.Do(t, asMod, "PATCH", path, `{"content":"moderated"}`); resp.Code != 403 {
+		t.Fatalf("moderator: status %d, want 403", resp.Code)
+	}
+
+	resp := handlertest.Do(t, asAlice, "PATCH", path, `{"content":"second"}`)
+	if resp.Code != 200 {
+		t.Fatalf("owner: status %d, want 200: %v", resp.Code, resp.Body)
+	}
+	resp = handlertest.Do(t, asAlice, "GET", path, "")
+	post := resp.Body["post"].(map[string]any)
+	if post["content"] != "second" || post["version"] != float64(2) || post["image_url"] != "https://example.com/a.png" {
+		t.Errorf("post after edit: %v", post)
+	}
+
+	// the same content again is not a new version
+	if resp := handlertest.Do(t, asAlice, "PATCH", path, `{"content":"second"}`); resp.Code != 200 {
+		t.Fatalf("unchanged: status %d, want 200: %v", resp.Code, resp.Body)
+	}
+	revisions, err := store.Repositories().Posts.ListRevisions(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 1 || revisions[0].Version != 1 || revisions[0].Content != "first" {
+		t.Errorf("revisions %+v, want the original post only", revisions)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		code   int
+	}{
+		{"put without image", "PUT", `{"content":"third"}`, 400},
+		{"nothing to update", "PATCH", `{}`, 400},
+		{"blank content", "PATCH", `{"content":"  "}`, 400},
+		{"invalid image", "PATCH", `{"image_url":"not a url"}`, 400},
+		{"put", "PUT", `{"content":"third","image_url":"https://example.com/b.png"}`, 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp := handlertest.Do(t, asAlice, tt.method, path, tt.body); resp.Code != tt.code {
+				t.Errorf("status %d, want %d: %v", resp.Code, tt.code, resp.Body)
+			}
+		})
+	}
+
+	// posts always have an image
+	resp = handlertest.Do(t, asAlice, "PATCH", path, `{"image_url":""}`)
+	if resp.Code != 400 || resp.ErrorCode() != "validation_failed" {
+		t.Errorf("empty image_url: status %d code %q, want 400 validation_failed", resp.Code, resp.ErrorCode())
+	}
+
+	if resp := handlertest.Do(t, asAlice, "PATCH", "/posts/999", `{"content":"x"}`); resp.Code != 404 {
+		t.Errorf("missing post: status %d, want 404", resp.Code)
+	}
+}
+
+func TestDeletePost(t *testing.T) {
+	store := memory.NewStore()
+	alice := handlertest.CreateUser(t, store, "alice")
+	bob := handlertest.CreateUser(t, store, "bob")
+	mod := handlertest.CreateUser(t, store, "mod")
+	asAlice := newServer(store, alice.ID)
+	body := `{"content":"hello","image_url":"https://example.com/a.png"}`
+	path := "/posts/" + strconv.Itoa(createPost(t, asAlice, body))
+
+	if resp := handlertest.Do(t, newServer(store, bob.ID), "DELETE", path, ""); resp.Code != 403 {
+		t.Fatalf("other user: status %d, want 403", resp.Code)
+	}
+	if resp := handlertest.Do(t, asAlice, "DELETE", path, ""); resp.Code != 200 {
+		t.Fatalf("owner: status %d, want 200: %v", resp.Code, resp.Body)
+	}
+	if resp := handlertest.Do(t, asAlice, "GET", path, ""); resp.Code != 404 {
+		t.Errorf("deleted post: status %d, want 404", resp.Code)
+	}
+
+	path = "/posts/" + strconv.Itoa(createPost(t, asAlice, body))
+	asMod := newServer(store, mod.ID, auth.RolesFor(auth.RoleModerator)...)
+	if resp := handlertest.Do(t, asMod, "DELETE", path, ""); resp.Code != 200 {
+		t.Fatalf("moderator: status %d, want 200: %v", resp.Code, resp.Body)
+	}
+	if resp := handlertest.Do(t, asMod, "GET", path, ""); resp.Code != 404 || resp.ErrorCode() != "not_found" {
+		t.Errorf("deleted post: status %d code %q, want 404 not_found", resp.Code, resp.ErrorCode())
+	}
+}
+
+func TestPostOfDeletedAccount(t *testing.T) {
+	store := memory.NewStore()
+	alice := handlertest.CreateUser(t, store, "alice")
+	bob := handlertest.CreateUser(t, store, "bob")
+	body := `{"content":"hello","image_url":"https://example.com/a.png"}`
+	path := "/posts/" + strconv.Itoa(createPost(t, newServer(store, alice.ID), body))
+	if err := store.Repositories().Users.SoftDelete(context.Background(), alice.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	asBob := newServer(store, bob.ID)
+	for _, p := range []string{path, path + "/comments", path + "/likes", path + "/revisions"} {
+		if resp := handlertest.Do(t, asBob, "GET", p, ""); resp.Code != 404 {
+			t.Errorf("GET %s: status %d, want 404", p, resp.Code)
+		}
+	}
+}
+
+func TestGetAllPostsPages(t *testing.T) {
+	store := memory.NewStore()
+	alice := handlertest.CreateUser(t, store, "alice")
+	e := newServer(store, alice.ID)
+	for i := 0; i < 3; i++ {
+		createPost(t, e, `{"content":"post","image_url":"https://example.com/a.png"}`)
+	}
+
+	var ids []float64
+	path := "/posts?limit=2"
+	for pages := 0; ; pages++ {
+		if pages == 3 {
+			t.Fatal("too many pages")
+		}
+		resp := handlertest.Do(t, e, "GET", path, "")
+		if resp.Code != 200 {
+			t.Fatalf("status %d: %v", resp.Code, resp.Body)
+		}
+		for _, p := range resp.Body["posts"].([]any) {
+			ids = append(ids, p.(map[string]any)["id"].(float64))
+		}
+		next := resp.Body["next_cursor"].(string)
+		if next == "" {
+			break
+		}
+		path = "/posts?limit=2&cursor=" + next
+	}
+	if len(ids) != 3 || ids[0] != 3 || ids[2] != 1 {
+		t.Errorf("ids %v, want the 3 posts newest first", ids)
+	}
+
+	resp := handlertest.Do(t, e, "GET", "/posts?cursor=garbage", "")
+	if resp.Code != 400 {
+		t.Errorf("bad cursor: status %d, want 400", resp.Code)
+	}
+}
+
+func TestGetPostCommentsMostLiked(t *testing.T) {
+	store := memory.NewStore()
+	alice := handlertest.CreateUser(t, store, "alice")
+	bob := handlertest.CreateUser(t, store, "bob")
+	e := newServer(store, alice.ID)
+	postID := createPost(t, e, `{"content":"post","image_url":"https://example.com/a.png"}`)
+
+	ctx := context.Background()
+	comments := store.Repositories().Comments
+	for i := 0; i < 3; i++ {
+		if err := comments.Create(ctx, &model.Comment{PostID: postID, AuthorID: alice.ID, Content: "comment"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// comment 2 gets two likes, comment 3 one
+	for _, like := range []struct{ comment, user int }{{2, alice.ID}, {2, bob.ID}, {3, bob.ID}} {
+		if _, err := comments.Like(ctx, like.comment, like.user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var ids []float64
+	path := "/posts/" + strconv.Itoa(postID) + "/comments?order=most_liked&limit=2"
+	for pages := 0; ; pages++ {
+		if pages == 3 {
+			t.Fatal("too many pages")
+		}
+		resp := handlertest.Do(t, e, "GET", path, "")
+		if resp.Code != 200 {
+			t.Fatalf("status %d: %v", resp.Code, resp.Body)
+		}
+		for _, c := range resp.Body["comments"].([]any) {
+			ids = append(ids, c.(map[string]any)["id"].(float64))
+		}
+		next := resp.Body["next_cursor"].(string)
+		if next == "" {
+			break
+		}
+		path = "/posts/" + strconv.Itoa(postID) + "/comments?order=most_liked&limit=2&cursor=" + next
+	}
+	if len(ids) != 3 || ids[0] != 2 || ids[1] != 3 || ids[2] != 1 {
+		t.Errorf("ids %v, want [2 3 1]", ids)
+	}
+
+	resp := handlertest.Do(t, e, "GET", "/posts/"+strconv.Itoa(postID)+"/comments?order=top", "")
+	if resp.Code != 400 {
+		t.Errorf("unknown order: status %d, want 400", resp.Code)
+	}
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository"
+)
+
+// ActivityRepository is the in-memory implementation of repository.ActivityRepository
+type ActivityRepository struct {
+	s *Store
+}
+
+func (r *ActivityRepository) Log(ctx context.Context, userID int, description string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[userID]; !ok {
+		return fmt.Errorf("failed to log activity: %w: user %d", repository.ErrInvalidReference, userID)
+	}
+
+	id := r.s.nextID("user_activity_logs")
+	r.s.activities[id] = model.Activity{
+		ID:          id,
+		UserID:      userID,
+		Description: description,
+		CreatedAt:   time.Now(),
+	}
+	return nil
+}
+
+func (r *ActivityRepository) ListByUser(ctx context.Context, userID int) ([]model.Activity, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	activities := []model.Activity{}
+	for _, activity := range r.s.activities {
+		if activity.UserID == userID {
+			activities = append(activities, activity)
+		}
+	}
+	// newest first, like the postgres implementation
+	sort.Slice(activities, func(i, j int) bool { return activities[i].ID > activities[j].ID })
+	return activities, nil
+}
@@ -0,0 +1,283 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository"
+)
+
+// primary key of comment_likes
+type commentLike struct {
+	commentID int
+	userID    int
+}
+
+// primary key of comment_reactions
+type commentReaction struct {
+	commentID int
+	userID    int
+	reaction  string
+}
+
+// CommentRepository is the in-memory implementation of repository.CommentRepository
+type CommentRepository struct {
+	s *Store
+}
+
+func (r *CommentRepository) Create(ctx context.Context, comment *model.Comment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[comment.AuthorID]; !ok {
+		return fmt.Errorf("%w: user %d", repository.ErrInvalidReference, comment.AuthorID)
+	}
+	if _, ok := r.s.posts[comment.PostID]; !ok {
+		return fmt.Errorf("%w: post %d", repository.ErrInvalidReference, comment.PostID)
+	}
+	if comment.ParentID != nil {
+		parent, ok := r.s.comments[*comment.ParentID]
+		if !ok {
+			return fmt.Errorf("%w: comment %d", repository.ErrInvalidReference, *comment.ParentID)
+		}
+		parent.ReplyCount++
+		r.s.comments[parent.ID] = parent
+	}
+
+	comment.ID = r.s.nextID("comments")
+	comment.Version = 1
+	comment.LikeCount = 0
+	comment.ReplyCount = 0
+	comment.CreatedAt = time.Now()
+	comment.Edited = false
+	comment.EditedAt = nil
+	comment.Reactions = model.ReactionCounts{}
+	r.s.comments[comment.ID] = *comment
+	return nil
+}
+
+func (r *CommentRepository) GetByID(ctx context.Context, id int) (*model.Comment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	comment, ok := r.s.comments[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	comment.Reactions = r.s.reactionCounts(id)
+	return &comment, nil
+}
+
+func (r *CommentRepository) GetDetail(ctx context.Context, id int) (*model.CommentDetail, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	comment, ok := r.s.comments[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	comment.Reactions = r.s.reactionCounts(id)
+	return &model.CommentDetail{
+		Comment:    comment,
+		PostTitle:  r.s.posts[comment.PostID].Content,
+		AuthorName: r.s.users[comment.AuthorID].FullName,
+	}, nil
+}
+
+func (r *CommentRepository) List(ctx context.Context, filter repository.CommentFilter) ([]model.PostComment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	desc := filter.Order != repository.OrderOldest
+	compare := func(a, b model.Comment) int { return a.CreatedAt.Compare(b.CreatedAt) }
+	if filter.Order == repository.OrderMostLiked {
+		compare = func(a, b model.Comment) int { return cmp.Compare(a.LikeCount, b.LikeCount) }
+	}
+
+	matches := []model.Comment{}
+	for _, comment := range r.s.comments {
+		if filter.ParentID != 0 && (comment.ParentID == nil || *comment.ParentID != filter.ParentID) {
+			continue
+		}
+		if filter.ParentID == 0 && (comment.PostID != filter.PostID || comment.ParentID != nil) {
+			continue
+		}
+		if after := filter.After; after != nil {
+			cursor := model.Comment{ID: after.ID, CreatedAt: after.Time, LikeCount: after.Count}
+			if !listedBefore(compare(cursor, comment), cursor.ID, comment.ID, desc) {
+				continue
+			}
+		}
+		matches = append(matches, comment)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return listedBefore(compare(matches[i], matches[j]), matches[i].ID, matches[j].ID, desc)
+	})
+
+	comments := []model.PostComment{}
+	for _, comment := range limit(matches, filter.Limit) {
+		comments = append(comments, model.PostComment{
+			ID:       comment.ID,
+			Content:  comment.Content,
+			ParentID: comment.ParentID,
+			Depth:    comment.Depth,
+			Author: model.CommentAuthor{
+				ID:   comment.AuthorID,
+				Name: r.s.users[comment.AuthorID].FullName,
+			},
+			LikeCount:  comment.LikeCount,
+			Reactions:  r.s.reactionCounts(comment.ID),
+			ReplyCount: comment.ReplyCount,
+			CreatedAt:  comment.CreatedAt,
+			Edited:     comment.Edited,
+			EditedAt:   comment.EditedAt,
+		})
+	}
+	return comments, nil
+}
+
+func (r *CommentRepository) CountByPost(ctx context.Context, postID int) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	count := 0
+	for _, comment := range r.s.comments {
+		if comment.PostID == postID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *CommentRepository) Update(ctx context.Context, comment *model.Comment, editorID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	old, ok := r.s.comments[comment.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	now := time.Now()
+	published := old.CreatedAt
+	if old.EditedAt != nil {
+		published = *old.EditedAt
+	}
+	revisionID := r.s.nextID("comment_revisions")
+	r.s.commentRevisions[revisionID] = model.CommentRevision{
+		ID:         revisionID,
+		CommentID:  old.ID,
+		Version:    old.Version,
+		Content:    old.Content,
+		CreatedAt:  published,
+		ReplacedAt: now,
+		ReplacedBy: &editorID,
+	}
+
+	updated := old
+	updated.Content = comment.Content
+	updated.Version = old.Version + 1
+	updated.Edited = true
+	updated.EditedAt = &now
+	r.s.comments[comment.ID] = updated
+	*comment = updated
+	comment.Reactions = r.s.reactionCounts(updated.ID)
+	return nil
+}
+
+func (r *CommentRepository) ListRevisions(ctx context.Context, commentID int) ([]model.CommentRevision, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	revisions := []model.CommentRevision{}
+	for _, revision := range r.s.commentRevisions {
+		if revision.CommentID == commentID {
+			revisions = append(revisions, revision)
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Version < revisions[j].Version })
+	return revisions, nil
+}
+
+func (r *CommentRepository) Delete(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.comments[id]; !ok {
+		return repository.ErrNotFound
+	}
+	r.s.deleteComment(id)
+	return nil
+}
+
+func (r *CommentRepository) Like(ctx context.Context, commentID, userID int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	comment, ok := r.s.comments[commentID]
+	if !ok {
+		return false, fmt.Errorf("%w: comment %d", repository.ErrInvalidReference, commentID)
+	}
+	if _, ok := r.s.users[userID]; !ok {
+		return false, fmt.Errorf("%w: user %d", repository.ErrInvalidReference, userID)
+	}
+
+	like := commentLike{commentID: commentID, userID: userID}
+	if r.s.commentLikes[like] {
+		return false, nil
+	}
+	r.s.commentLikes[like] = true
+	comment.LikeCount++
+	r.s.comments[commentID] = comment
+	return true, nil
+}
+
+func (r *CommentRepository) Unlike(ctx context.Context, commentID, userID int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	like := commentLike{commentID: commentID, userID: userID}
+	if !r.s.commentLikes[like] {
+		return false, nil
+	}
+	r.s.unlikeComment(like)
+	return true, nil
+}
+
+func (r *CommentRepository) AddReaction(ctx context.Context, commentID, userID int, reaction string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.comments[commentID]; !ok {
+		return false, fmt.Errorf("%w: comment %d", repository.ErrInvalidReference, commentID)
+	}
+	if _, ok := r.s.users[userID]; !ok {
+		return false, fmt.Errorf("%w: user %d", repository.ErrInvalidReference, userID)
+	}
+	if !model.ValidReaction(reaction) {
+		return false, fmt.Errorf("%w: unknown reaction %q", repository.ErrConstraint, reaction)
+	}
+
+	key := commentReaction{commentID: commentID, userID: userID, reaction: reaction}
+	if r.s.commentReactions[key] {
+		return false, nil
+	}
+	r.s.commentReactions[key] = true
+	return true, nil
+}
+
+func (r *CommentRepository) RemoveReaction(ctx context.Context, commentID, userID int, reaction string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key := commentReaction{commentID: commentID, userID: userID, reaction: reaction}
+	if !r.s.commentReactions[key] {
+		return false, nil
+	}
+	delete(r.s.commentReactions, key)
+	return true, nil
+}
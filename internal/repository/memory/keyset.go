@@ -0,0 +1,25 @@
+package memory
+
+import "cmp"
+
+// listedBefore reports whether row a comes before row b in a listing ordered
+// by (key, id), keyCmp being the comparison of a's key with b's. Rows after a
+// cursor are those the cursor is listed before.
+func listedBefore(keyCmp int, aID, bID int, desc bool) bool {
+	c := keyCmp
+	if c == 0 {
+		c = cmp.Compare(aID, bID)
+	}
+	if desc {
+		return c > 0
+	}
+	return c < 0
+}
+
+// limit truncates items to at most n elements
+func limit[T any](items []T, n int) []T {
+	if n > 0 && len(items) > n {
+		return items[:n]
+	}
+	return items
+}
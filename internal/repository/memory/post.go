@@ -0,0 +1,249 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository"
+)
+
+// primary key of post_likes
+type postLike struct {
+	postID int
+	userID int
+}
+
+// PostRepository is the in-memory implementation of repository.PostRepository
+type PostRepository struct {
+	s *Store
+}
+
+func (r *PostRepository) Create(ctx context.Context, post *model.Post) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[post.UserID]; !ok {
+		return fmt.Errorf("%w: user %d", repository.ErrInvalidReference, post.UserID)
+	}
+
+	post.ID = r.s.nextID("posts")
+	post.Version = 1
+	post.LikeCount = 0
+	post.LikedByMe = false
+	post.CreatedAt = time.Now()
+	post.EditedAt = nil
+	r.s.posts[post.ID] = *post
+	return nil
+}
+
+func (r *PostRepository) List(ctx context.Context, filter repository.PostFilter) ([]model.Post, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	desc := filter.Order != repository.OrderOldest
+	posts := []model.Post{}
+	for _, post := range r.s.posts {
+		if filter.UserID != 0 && post.UserID != filter.UserID {
+			continue
+		}
+		if r.s.users[post.UserID].DeletedAt != nil {
+			continue
+		}
+		if after := filter.After; after != nil && !listedBefore(after.Time.Compare(post.CreatedAt), after.ID, post.ID, desc) {
+			continue
+		}
+		posts = append(posts, post)
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		return listedBefore(posts[i].CreatedAt.Compare(posts[j].CreatedAt), posts[i].ID, posts[j].ID, desc)
+	})
+	return limit(posts, filter.Limit), nil
+}
+
+func (r *PostRepository) Feed(ctx context.Context, filter repository.FeedFilter) ([]model.Post, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	authors := map[int]bool{filter.UserID: true}
+	for f := range r.s.follows {
+		if f.followerID == filter.UserID && r.s.users[f.followeeID].DeletedAt == nil {
+			authors[f.followeeID] = true
+		}
+	}
+
+	posts := []model.Post{}
+	for _, post := range r.s.posts {
+		if !authors[post.UserID] {
+			continue
+		}
+		if after := filter.After; after != nil && !listedBefore(after.Time.Compare(post.CreatedAt), after.ID, post.ID, true) {
+			continue
+		}
+		posts = append(posts, post)
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		return listedBefore(posts[i].CreatedAt.Compare(posts[j].CreatedAt), posts[i].ID, posts[j].ID, true)
+	})
+	return limit(posts, filter.Limit), nil
+}
+
+func (r *PostRepository) GetByID(ctx context.Context, id int) (*model.Post, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	post, ok := r.s.posts[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &post, nil
+}
+
+func (r *PostRepository) GetActiveByID(ctx context.Context, id int) (*model.Post, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	post, ok := r.s.posts[id]
+	if !ok || r.s.users[post.UserID].DeletedAt != nil {
+		return nil, repository.ErrNotFound
+	}
+	return &post, nil
+}
+
+func (r *PostRepository) Update(ctx context.Context, post *model.Post) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	old, ok := r.s.posts[post.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	now := time.Now()
+	published := old.CreatedAt
+	if old.EditedAt != nil {
+		published = *old.EditedAt
+	}
+	revisionID := r.s.nextID("post_revisions")
+	r.s.postRevisions[revisionID] = model.PostRevision{
+		ID:         revisionID,
+		PostID:     old.ID,
+		Version:    old.Version,
+		Content:    old.Content,
+		ImageURL:   old.ImageURL,
+		CreatedAt:  published,
+		ReplacedAt: now,
+	}
+
+	updated := old
+	updated.Content = post.Content
+	updated.ImageURL = post.ImageURL
+	updated.Version = old.Version + 1
+	updated.EditedAt = &now
+	r.s.posts[post.ID] = updated
+	*post = updated
+	return nil
+}
+
+func (r *PostRepository) ListRevisions(ctx context.Context, postID int) ([]model.PostRevision, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	revisions := []model.PostRevision{}
+	for _, revision := range r.s.postRevisions {
+		if revision.PostID == postID {
+			revisions = append(revisions, revision)
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Version < revisions[j].Version })
+	return revisions, nil
+}
+
+func (r *PostRepository) Delete(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.posts[id]; !ok {
+		return repository.ErrNotFound
+	}
+	r.s.deletePost(id)
+	return nil
+}
+
+func (r *PostRepository) Like(ctx context.Context, postID, userID int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	post, ok := r.s.posts[postID]
+	if !ok {
+		return false, fmt.Errorf("%w: post %d", repository.ErrInvalidReference, postID)
+	}
+	if _, ok := r.s.users[userID]; !ok {
+		return false, fmt.Errorf("%w: user %d", repository.ErrInvalidReference, userID)
+	}
+
+	like := postLike{postID: postID, userID: userID}
+	if _, ok := r.s.postLikes[like]; ok {
+		return false, nil
+	}
+	r.s.postLikes[like] = time.Now()
+	post.LikeCount++
+	r.s.posts[postID] = post
+	return true, nil
+}
+
+func (r *PostRepository) Unlike(ctx context.Context, postID, userID int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	like := postLike{postID: postID, userID: userID}
+	if _, ok := r.s.postLikes[like]; !ok {
+		return false, nil
+	}
+	r.s.unlikePost(like)
+	return true, nil
+}
+
+func (r *PostRepository) LikedBy(ctx context.Context, userID int, postIDs []int) (map[int]bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	liked := map[int]bool{}
+	for _, postID := range postIDs {
+		if _, ok := r.s.postLikes[postLike{postID: postID, userID: userID}]; ok {
+			liked[postID] = true
+		}
+	}
+	return liked, nil
+}
+
+func (r *PostRepository) ListLikers(ctx context.Context, filter repository.LikeFilter) ([]model.PostLiker, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	likers := []model.PostLiker{}
+	for like, likedAt := range r.s.postLikes {
+		if like.postID != filter.PostID {
+			continue
+		}
+		if after := filter.After; after != nil && !listedBefore(after.Time.Compare(likedAt), after.ID, like.userID, true) {
+			continue
+		}
+		user := r.s.users[like.userID]
+		if user.DeletedAt != nil {
+			continue
+		}
+		likers = append(likers, model.PostLiker{
+			UserID:   user.ID,
+			Username: user.Username,
+			FullName: user.FullName,
+			LikedAt:  likedAt,
+		})
+	}
+	sort.Slice(likers, func(i, j int) bool {
+		return listedBefore(likers[i].LikedAt.Compare(likers[j].LikedAt), likers[i].UserID, likers[j].UserID, true)
+	})
+	return limit(likers, filter.Limit), nil
+}
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository"
+)
+
+// RefreshTokenRepository is the in-memory implementation of repository.RefreshTokenRepository
+type RefreshTokenRepository struct {
+	s *Store
+}
+
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *model.RefreshToken) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[token.UserID]; !ok {
+		return fmt.Errorf("%w: user %d", repository.ErrInvalidReference, token.UserID)
+	}
+	for _, t := range r.s.refresh {
+		if t.TokenHash == token.TokenHash {
+			return repository.ErrDuplicate
+		}
+	}
+
+	token.ID = r.s.nextID("refresh_tokens")
+	token.CreatedAt = time.Now()
+	r.s.refresh[token.ID] = *token
+	return nil
+}
+
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, hash string) (*model.RefreshToken, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, token := range r.s.refresh {
+		if token.TokenHash == hash {
+			return &token, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	token, ok := r.s.refresh[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	r.s.refresh[id] = token
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	for id, token := range r.s.refresh {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			r.s.refresh[id] = token
+		}
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	for id, token := range r.s.refresh {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			r.s.refresh[id] = token
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"w3/gc3/internal/repository"
+)
+
+type revokedToken struct {
+	userID    int
+	expiresAt time.Time
+}
+
+// SessionRepository is the in-memory implementation of repository.SessionRepository
+type SessionRepository struct {
+	s *Store
+}
+
+func (r *SessionRepository) RevokeAccessToken(ctx context.Context, jti string, userID int, expiresAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.revoked[jti]; !ok {
+		r.s.revoked[jti] = revokedToken{userID: userID, expiresAt: expiresAt}
+	}
+	return nil
+}
+
+func (r *SessionRepository) RevokeAll(ctx context.Context, userID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[userID]; !ok {
+		return repository.ErrNotFound
+	}
+	r.s.versions[userID]++
+	return nil
+}
+
+func (r *SessionRepository) TokenVersion(ctx context.Context, userID int) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[userID]; !ok {
+		return 0, repository.ErrNotFound
+	}
+	return r.s.versions[userID], nil
+}
+
+func (r *SessionRepository) IsRevoked(ctx context.Context, jti string, userID int, tokenVersion int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.revoked[jti]; ok {
+		return true, nil
+	}
+	if _, ok := r.s.users[userID]; !ok {
+		return true, nil
+	}
+	return r.s.versions[userID] != tokenVersion, nil
+}
+
+func (r *SessionRepository) PurgeExpired(ctx context.Context) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	for jti, token := range r.s.revoked {
+		if token.expiresAt.Before(now) {
+			delete(r.s.revoked, jti)
+		}
+	}
+	return nil
+}
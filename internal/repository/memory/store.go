@@ -0,0 +1,376 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository"
+)
+
+// Store keeps every table in process memory. It mirrors the postgres schema
+// closely enough (unique emails/usernames, ON DELETE CASCADE) to stand in for
+// the database in tests and local experiments.
+type Store struct {
+	mu sync.Mutex
+	// serializes units of work, see Do
+	txMu sync.Mutex
+
+	users            map[int]model.User
+	posts            map[int]model.Post
+	postRevisions    map[int]model.PostRevision
+	postLikes        map[postLike]time.Time // like time by (post, user)
+	comments         map[int]model.Comment
+	commentRevisions map[int]model.CommentRevision
+	commentLikes     map[commentLike]bool
+	commentReactions map[commentReaction]bool
+	follows          map[follow]time.Time // follow time by (follower, followee)
+	activities       map[int]model.Activity
+	refresh          map[int]model.RefreshToken
+	revoked          map[string]revokedToken // by jti
+	versions         map[int]int             // token version by user id
+
+	// last issued id per table, like a SERIAL sequence
+	seq map[string]int
+}
+
+func NewStore() *Store {
+	return &Store{
+		users:            map[int]model.User{},
+		posts:            map[int]model.Post{},
+		postRevisions:    map[int]model.PostRevision{},
+		postLikes:        map[postLike]time.Time{},
+		comments:         map[int]model.Comment{},
+		commentRevisions: map[int]model.CommentRevision{},
+		commentLikes:     map[commentLike]bool{},
+		commentReactions: map[commentReaction]bool{},
+		follows:          map[follow]time.Time{},
+		activities:       map[int]model.Activity{},
+		refresh:          map[int]model.RefreshToken{},
+		revoked:          map[string]revokedToken{},
+		versions:         map[int]int{},
+		seq:              map[string]int{},
+	}
+}
+
+// Repositories returns every repository backed by this store
+func (s *Store) Repositories() repository.Repositories {
+	return repository.Repositories{
+		Users:         &UserRepository{s},
+		Posts:         &PostRepository{s},
+		Comments:      &CommentRepository{s},
+		Activities:    &ActivityRepository{s},
+		RefreshTokens: &RefreshTokenRepository{s},
+		Sessions:      &SessionRepository{s},
+	}
+}
+
+// deleteUser removes a user and, like ON DELETE CASCADE, every row
+// referencing them; callers must hold s.mu
+func (s *Store) deleteUser(id int) {
+	// unfollow while the user is still there to tell whether the follows
+	// were counted
+	for f := range s.follows {
+		if f.followerID == id || f.followeeID == id {
+			s.unfollow(f)
+		}
+	}
+	delete(s.users, id)
+	delete(s.versions, id)
+
+	for postID, post := range s.posts {
+		if post.UserID == id {
+			s.deletePost(postID)
+		}
+	}
+	for commentID, comment := range s.comments {
+		if comment.AuthorID == id {
+			s.deleteComment(commentID)
+		}
+	}
+	for like := range s.postLikes {
+		if like.userID == id {
+			s.unlikePost(like)
+		}
+	}
+	for like := range s.commentLikes {
+		if like.userID == id {
+			s.unlikeComment(like)
+		}
+	}
+	for reaction := range s.commentReactions {
+		if reaction.userID == id {
+			delete(s.commentReactions, reaction)
+		}
+	}
+	for revisionID, revision := range s.commentRevisions {
+		if revision.ReplacedBy != nil && *revision.ReplacedBy == id {
+			revision.ReplacedBy = nil
+			s.commentRevisions[revisionID] = revision
+		}
+	}
+	for activityID, activity := range s.activities {
+		if activity.UserID == id {
+			delete(s.activities, activityID)
+		}
+	}
+	for tokenID, token := range s.refresh {
+		if token.UserID == id {
+			delete(s.refresh, tokenID)
+		}
+	}
+	for jti, token := range s.revoked {
+		if token.userID == id {
+			delete(s.revoked, jti)
+		}
+	}
+}
+
+// deletePost removes a post with its revisions and comments; callers must hold s.mu
+func (s *Store) deletePost(id int) {
+	delete(s.posts, id)
+	for revisionID, revision := range s.postRevisions {
+		if revision.PostID == id {
+			delete(s.postRevisions, revisionID)
+		}
+	}
+	for like := range s.postLikes {
+		if like.postID == id {
+			delete(s.postLikes, like)
+		}
+	}
+	for commentID, comment := range s.comments {
+		if comment.PostID == id {
+			s.deleteComment(commentID)
+		}
+	}
+}
+
+// deleteComment removes a comment with its replies, revisions, likes and reactions;
+// callers must hold s.mu
+func (s *Store) deleteComment(id int) {
+	comment, ok := s.comments[id]
+	if !ok {
+		return
+	}
+	delete(s.comments, id)
+	if comment.ParentID != nil {
+		if parent, ok := s.comments[*comment.ParentID]; ok {
+			parent.ReplyCount--
+			s.comments[parent.ID] = parent
+		}
+	}
+	for replyID, reply := range s.comments {
+		if reply.ParentID != nil && *reply.ParentID == id {
+			s.deleteComment(replyID)
+		}
+	}
+	for revisionID, revision := range s.commentRevisions {
+		if revision.CommentID == id {
+			delete(s.commentRevisions, revisionID)
+		}
+	}
+	for like := range s.commentLikes {
+		if like.commentID == id {
+			delete(s.commentLikes, like)
+		}
+	}
+	for reaction := range s.commentReactions {
+		if reaction.commentID == id {
+			delete(s.commentReactions, reaction)
+		}
+	}
+}
+
+// unlikePost removes a like and updates the post's like count like the
+// postgres trigger does; callers must hold s.mu
+func (s *Store) unlikePost(like postLike) {
+	delete(s.postLikes, like)
+	if post, ok := s.posts[like.postID]; ok {
+		post.LikeCount--
+		s.posts[like.postID] = post
+	}
+}
+
+// unlikeComment removes a like and updates the comment's like count like the
+// postgres trigger does; callers must hold s.mu
+func (s *Store) unlikeComment(like commentLike) {
+	delete(s.commentLikes, like)
+	if comment, ok := s.comments[like.commentID]; ok {
+		comment.LikeCount--
+		s.comments[like.commentID] = comment
+	}
+}
+
+// unfollow removes a follow and updates the follow counts of both users like
+// the postgres trigger does; callers must hold s.mu
+func (s *Store) unfollow(f follow) {
+	delete(s.follows, f)
+	if s.followCounted(f) {
+		s.countFollow(f, -1)
+	}
+}
+
+// followCounted reports whether f is in the follow counts, which only count
+// the follows between two users that are not deleted, like follow_counted in
+// postgres; callers must hold s.mu
+func (s *Store) followCounted(f follow) bool {
+	follower, ok := s.users[f.followerID]
+	if !ok || follower.DeletedAt != nil {
+		return false
+	}
+	followee, ok := s.users[f.followeeID]
+	return ok && followee.DeletedAt == nil
+}
+
+// countFollow adds delta to the follow counts of both users of f; callers
+// must hold s.mu
+func (s *Store) countFollow(f follow, delta int) {
+	follower := s.users[f.followerID]
+	follower.FollowingCount += delta
+	s.users[f.followerID] = follower
+	followee := s.users[f.followeeID]
+	followee.FollowerCount += delta
+	s.users[f.followeeID] = followee
+}
+
+// setDeleted marks the user with id deleted or not and moves their follows in
+// or out of the counts of the others like trg_count_follows_of_user does;
+// callers must hold s.mu
+func (s *Store) setDeleted(id int, deletedAt *time.Time) {
+	user := s.users[id]
+	wasDeleted := user.DeletedAt != nil
+	user.DeletedAt = deletedAt
+	s.users[id] = user
+	if wasDeleted == (deletedAt != nil) {
+		return
+	}
+
+	delta := 1
+	if deletedAt != nil {
+		delta = -1
+	}
+	for f := range s.follows {
+		if f.followerID == id {
+			if followee := s.users[f.followeeID]; followee.DeletedAt == nil {
+				followee.FollowerCount += delta
+				s.users[f.followeeID] = followee
+			}
+		}
+		if f.followeeID == id {
+			if follower := s.users[f.followerID]; follower.DeletedAt == nil {
+				follower.FollowingCount += delta
+				s.users[f.followerID] = follower
+			}
+		}
+	}
+	if deletedAt != nil {
+		return
+	}
+
+	// the counts of the user missed whoever was deleted or restored while it
+	// was deleted itself
+	user.FollowerCount, user.FollowingCount = 0, 0
+	for f := range s.follows {
+		if f.followeeID == id && s.users[f.followerID].DeletedAt == nil {
+			user.FollowerCount++
+		}
+		if f.followerID == id && s.users[f.followeeID].DeletedAt == nil {
+			user.FollowingCount++
+		}
+	}
+	s.users[id] = user
+}
+
+// reactionCounts aggregates the reactions to a comment; callers must hold s.mu
+func (s *Store) reactionCounts(commentID int) model.ReactionCounts {
+	counts := model.ReactionCounts{}
+	for reaction := range s.commentReactions {
+		if reaction.commentID == commentID {
+			counts[reaction.reaction]++
+		}
+	}
+	return counts
+}
+
+// callers must hold s.mu
+func (s *Store) nextID(table string) int {
+	s.seq[table]++
+	return s.seq[table]
+}
+
+// Do implements repository.UnitOfWork. Units of work run one at a time and
+// every table is restored if fn fails; unlike postgres, plain reads made
+// concurrently can observe the uncommitted writes.
+func (s *Store) Do(ctx context.Context, fn func(repos repository.Repositories) error) error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	s.mu.Lock()
+	snapshot := s.clone()
+	s.mu.Unlock()
+
+	if err := fn(s.Repositories()); err != nil {
+		s.mu.Lock()
+		s.restore(snapshot)
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// tableSet holds a copy of every table, callers must hold s.mu
+type tableSet struct {
+	users            map[int]model.User
+	posts            map[int]model.Post
+	postRevisions    map[int]model.PostRevision
+	postLikes        map[postLike]time.Time
+	comments         map[int]model.Comment
+	commentRevisions map[int]model.CommentRevision
+	commentLikes     map[commentLike]bool
+	commentReactions map[commentReaction]bool
+	follows          map[follow]time.Time
+	activities       map[int]model.Activity
+	refresh          map[int]model.RefreshToken
+	revoked          map[string]revokedToken
+	versions         map[int]int
+	seq              map[string]int
+}
+
+func (s *Store) clone() tableSet {
+	return tableSet{
+		users:            maps.Clone(s.users),
+		posts:            maps.Clone(s.posts),
+		postRevisions:    maps.Clone(s.postRevisions),
+		postLikes:        maps.Clone(s.postLikes),
+		comments:         maps.Clone(s.comments),
+		commentRevisions: maps.Clone(s.commentRevisions),
+		commentLikes:     maps.Clone(s.commentLikes),
+		commentReactions: maps.Clone(s.commentReactions),
+		follows:          maps.Clone(s.follows),
+		activities:       maps.Clone(s.activities),
+		refresh:          maps.Clone(s.refresh),
+		revoked:          maps.Clone(s.revoked),
+		versions:         maps.Clone(s.versions),
+		seq:              maps.Clone(s.seq),
+	}
+}
+
+func (s *Store) restore(t tableSet) {
+	s.users = t.users
+	s.posts = t.posts
+	s.postRevisions = t.postRevisions
+	s.postLikes = t.postLikes
+	s.comments = t.comments
+	s.commentRevisions = t.commentRevisions
+	s.commentLikes = t.commentLikes
+	s.commentReactions = t.commentReactions
+	s.follows = t.follows
+	s.activities = t.activities
+	s.refresh = t.refresh
+	s.revoked = t.revoked
+	s.versions = t.versions
+	s.seq = t.seq
+}
@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository"
+)
+
+// a failed unit of work leaves nothing behind, like a rolled back transaction
+func TestDoRollsBack(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	user := &model.User{FullName: "Alice", Email: "alice@example.com", Username: "alice", Password: "x", Age: 30}
+	if err := store.Repositories().Users.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	failed := errors.New("failed")
+	err := store.Do(ctx, func(repos repository.Repositories) error {
+		if err := repos.Posts.Create(ctx, &model.Post{UserID: user.ID, Content: "hi"}); err != nil {
+			return err
+		}
+		if err := repos.Activities.Log(ctx, user.ID, "created a post"); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Do: %v, want %v", err, failed)
+	}
+
+	if _, err := store.Repositories().Posts.GetByID(ctx, 1); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("post after rollback: %v, want ErrNotFound", err)
+	}
+	activities, err := store.Repositories().Activities.ListByUser(ctx, user.ID)
+	if err != nil || len(activities) != 0 {
+		t.Errorf("activities after rollback: %v %v, want none", activities, err)
+	}
+
+	// ids handed out inside the failed unit of work are given out again
+	post := &model.Post{UserID: user.ID, Content: "hi"}
+	if err := store.Repositories().Posts.Create(ctx, post); err != nil || post.ID != 1 {
+		t.Errorf("post id %d: %v, want 1", post.ID, err)
+	}
+}
+
+// the memory store fails like the postgres one, see postgres.translate
+func TestConstraintErrors(t *testing.T) {
+	ctx := context.Background()
+	repos := NewStore().Repositories()
+	user := &model.User{FullName: "Alice", Email: "alice@example.com", Username: "alice", Password: "x", Age: 30}
+	if err := repos.Users.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"duplicate user", repos.Users.Create(ctx, &model.User{Email: "alice@example.com", Username: "other"}), repository.ErrDuplicate},
+		{"post of a missing user", repos.Posts.Create(ctx, &model.Post{UserID: 99}), repository.ErrInvalidReference},
+		{"comment on a missing post", repos.Comments.Create(ctx, &model.Comment{AuthorID: user.ID, PostID: 99, Content: "hi"}), repository.ErrInvalidReference},
+		{"follow of a missing user", second(repos.Users.Follow(ctx, user.ID, 99)), repository.ErrInvalidReference},
+		{"self follow", second(repos.Users.Follow(ctx, user.ID, user.ID)), repository.ErrConstraint},
+		{"missing post", second(repos.Posts.GetByID(ctx, 99)), repository.ErrNotFound},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: %v, want %v", tt.name, tt.err, tt.want)
+		}
+	}
+}
+
+func second[T any](_ T, err error) error {
+	return err
+}
+
+// deleted accounts drop out of every listing, see postgres.PostRepository.List
+func TestDeletedAccountsAreHidden(t *testing.T) {
+	ctx := context.Background()
+	repos := NewStore().Repositories()
+	users := map[string]*model.User{}
+	for _, name := range []string{"alice", "bob", "carol"} {
+		users[name] = &model.User{FullName: name, Email: name + "@example.com", Username: name, Password: "x", Age: 30}
+		if err := repos.Users.Create(ctx, users[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	alice, bob, carol := users["alice"].ID, users["bob"].ID, users["carol"].ID
+
+	post := &model.Post{UserID: alice, Content: "hi"}
+	for _, err := range []error{
+		repos.Posts.Create(ctx, post),
+		repos.Posts.Create(ctx, &model.Post{UserID: carol, Content: "bye"}),
+		second(repos.Posts.Like(ctx, post.ID, bob)),
+		second(repos.Posts.Like(ctx, post.ID, carol)),
+		second(repos.Users.Follow(ctx, alice, carol)),
+		second(repos.Users.Follow(ctx, carol, alice)),
+		second(repos.Users.Follow(ctx, bob, alice)),
+		repos.Users.SoftDelete(ctx, carol),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	posts, err := repos.Posts.List(ctx, repository.PostFilter{Limit: 10})
+	if err != nil || len(posts) != 1 || posts[0].UserID != alice {
+		t.Errorf("List: %v %v, want only alice's post", posts, err)
+	}
+	feed, err := repos.Posts.Feed(ctx, repository.FeedFilter{UserID: alice, Limit: 10})
+	if err != nil || len(feed) != 1 || feed[0].UserID != alice {
+		t.Errorf("Feed: %v %v, want only alice's post", feed, err)
+	}
+	likers, err := repos.Posts.ListLikers(ctx, repository.LikeFilter{PostID: post.ID, Limit: 10})
+	if err != nil || len(likers) != 1 || likers[0].UserID != bob {
+		t.Errorf("ListLikers: %v %v, want only bob", likers, err)
+	}
+	followers, err := repos.Users.ListFollowers(ctx, repository.FollowFilter{UserID: alice, Limit: 10})
+	if err != nil || len(followers) != 1 || followers[0].UserID != bob {
+		t.Errorf("ListFollowers: %v %v, want only bob", followers, err)
+	}
+	following, err := repos.Users.ListFollowing(ctx, repository.FollowFilter{UserID: alice, Limit: 10})
+	if err != nil || len(following) != 0 {
+		t.Errorf("ListFollowing: %v %v, want none", following, err)
+	}
+}
+
+// the follow counts only count follows between users that are not deleted,
+// like the count_follows triggers
+func TestFollowCountsSkipDeletedUsers(t *testing.T) {
+	ctx := context.Background()
+	repos := NewStore().Repositories()
+	users := map[string]*model.User{}
+	for _, name := range []string{"alice", "bob", "carol"} {
+		users[name] = &model.User{FullName: name, Email: name + "@example.com", Username: name, Password: "x", Age: 30}
+		if err := repos.Users.Create(ctx, users[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	alice, bob, carol := users["alice"].ID, users["bob"].ID, users["carol"].ID
+
+	counts := func(step string, id, followers, following int) {
+		t.Helper()
+		user, err := repos.Users.GetByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.FollowerCount != followers || user.FollowingCount != following {
+			t.Errorf("%s: user %d counts %d/%d, want %d/%d", step, id, user.FollowerCount, user.FollowingCount, followers, following)
+		}
+	}
+
+	for _, err := range []error{
+		second(repos.Users.Follow(ctx, alice, carol)),
+		second(repos.Users.Follow(ctx, carol, alice)),
+		second(repos.Users.Follow(ctx, bob, alice)),
+		repos.Users.SoftDelete(ctx, carol),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	counts("soft delete", alice, 1, 0)
+
+	// a follow of a deleted user only counts once they are restored
+	if err := second(repos.Users.Follow(ctx, bob, carol)); err != nil {
+		t.Fatal(err)
+	}
+	counts("follow deleted", bob, 0, 1)
+
+	if err := repos.Users.Restore(ctx, carol); err != nil {
+		t.Fatal(err)
+	}
+	counts("restore", alice, 2, 1)
+	counts("restore", bob, 0, 2)
+	counts("restore", carol, 2, 1)
+
+	if err := repos.Users.SoftDelete(ctx, carol); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repos.Users.PurgeDeleted(ctx, time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	counts("purge", alice, 1, 0)
+	counts("purge", bob, 0, 1)
+}
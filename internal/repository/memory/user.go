@@ -0,0 +1,280 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository"
+)
+
+// primary key of follows
+type follow struct {
+	followerID int
+	followeeID int
+}
+
+// UserRepository is the in-memory implementation of repository.UserRepository
+type UserRepository struct {
+	s *Store
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, u := range r.s.users {
+		if u.Email == user.Email || u.Username == user.Username {
+			return repository.ErrDuplicate
+		}
+	}
+
+	user.ID = r.s.nextID("users")
+	user.Role = "user"
+	user.CreatedAt = time.Now()
+	r.s.users[user.ID] = *user
+	return nil
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id int) (*model.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user, ok := r.s.users[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetActiveByID(ctx context.Context, id int) (*model.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user, ok := r.s.users[id]
+	if !ok || user.DeletedAt != nil {
+		return nil, repository.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, user := range r.s.users {
+		if user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *UserRepository) List(ctx context.Context) ([]model.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	users := make([]model.User, 0, len(r.s.users))
+	for _, user := range r.s.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (r *UserRepository) GetProfile(ctx context.Context, username string) (*model.UserProfile, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, user := range r.s.users {
+		if user.Username != username || user.DeletedAt != nil {
+			continue
+		}
+		profile := model.UserProfile{
+			ID:             user.ID,
+			Username:       user.Username,
+			FullName:       user.FullName,
+			Bio:            user.Bio,
+			AvatarURL:      user.AvatarURL,
+			FollowerCount:  user.FollowerCount,
+			FollowingCount: user.FollowingCount,
+			CreatedAt:      user.CreatedAt,
+		}
+		for _, post := range r.s.posts {
+			if post.UserID == user.ID {
+				profile.PostCount++
+			}
+		}
+		return &profile, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *UserRepository) UpdateProfile(ctx context.Context, user *model.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored, ok := r.s.users[user.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	stored.FullName = user.FullName
+	stored.Bio = user.Bio
+	stored.AvatarURL = user.AvatarURL
+	r.s.users[user.ID] = stored
+	return nil
+}
+
+func (r *UserRepository) UpdateRole(ctx context.Context, id int, role string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user, ok := r.s.users[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	user.Role = role
+	r.s.users[id] = user
+	return nil
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id int, hash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user, ok := r.s.users[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	user.Password = hash
+	r.s.users[id] = user
+	return nil
+}
+
+func (r *UserRepository) SoftDelete(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user, ok := r.s.users[id]
+	if !ok || user.DeletedAt != nil {
+		return repository.ErrNotFound
+	}
+	now := time.Now()
+	r.s.setDeleted(id, &now)
+	return nil
+}
+
+func (r *UserRepository) Restore(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[id]; !ok {
+		return repository.ErrNotFound
+	}
+	r.s.setDeleted(id, nil)
+	return nil
+}
+
+func (r *UserRepository) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	purged := 0
+	for id, user := range r.s.users {
+		if user.DeletedAt != nil && user.DeletedAt.Before(before) {
+			r.s.deleteUser(id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[id]; !ok {
+		return repository.ErrNotFound
+	}
+	r.s.deleteUser(id)
+	return nil
+}
+
+func (r *UserRepository) Follow(ctx context.Context, followerID, followeeID int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[followerID]; !ok {
+		return false, fmt.Errorf("%w: user %d", repository.ErrInvalidReference, followerID)
+	}
+	if _, ok := r.s.users[followeeID]; !ok {
+		return false, fmt.Errorf("%w: user %d", repository.ErrInvalidReference, followeeID)
+	}
+	if followerID == followeeID {
+		return false, fmt.Errorf("%w: user %d cannot follow themselves", repository.ErrConstraint, followerID)
+	}
+
+	f := follow{followerID: followerID, followeeID: followeeID}
+	if _, ok := r.s.follows[f]; ok {
+		return false, nil
+	}
+	r.s.follows[f] = time.Now()
+	if r.s.followCounted(f) {
+		r.s.countFollow(f, 1)
+	}
+	return true, nil
+}
+
+func (r *UserRepository) Unfollow(ctx context.Context, followerID, followeeID int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	f := follow{followerID: followerID, followeeID: followeeID}
+	if _, ok := r.s.follows[f]; !ok {
+		return false, nil
+	}
+	r.s.unfollow(f)
+	return true, nil
+}
+
+func (r *UserRepository) ListFollowers(ctx context.Context, filter repository.FollowFilter) ([]model.Follow, error) {
+	return r.listFollows(filter, func(f follow) (int, int) { return f.followeeID, f.followerID })
+}
+
+func (r *UserRepository) ListFollowing(ctx context.Context, filter repository.FollowFilter) ([]model.Follow, error) {
+	return r.listFollows(filter, func(f follow) (int, int) { return f.followerID, f.followeeID })
+}
+
+// listFollows pages through the follows of filter.UserID, sides telling which
+// user of a follow is filter.UserID and which one gets listed
+func (r *UserRepository) listFollows(filter repository.FollowFilter, sides func(follow) (user, other int)) ([]model.Follow, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	follows := []model.Follow{}
+	for f, followedAt := range r.s.follows {
+		userID, otherID := sides(f)
+		if userID != filter.UserID {
+			continue
+		}
+		if after := filter.After; after != nil && !listedBefore(after.Time.Compare(followedAt), after.ID, otherID, true) {
+			continue
+		}
+		other := r.s.users[otherID]
+		if other.DeletedAt != nil {
+			continue
+		}
+		follows = append(follows, model.Follow{
+			UserID:     other.ID,
+			Username:   other.Username,
+			FullName:   other.FullName,
+			FollowedAt: followedAt,
+		})
+	}
+	sort.Slice(follows, func(i, j int) bool {
+		return listedBefore(follows[i].FollowedAt.Compare(follows[j].FollowedAt), follows[i].UserID, follows[j].UserID, true)
+	})
+	return limit(follows, filter.Limit), nil
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"w3/gc3/internal/model"
+)
+
+// ActivityRepository is the pgx implementation of repository.ActivityRepository
+type ActivityRepository struct {
+	db DBTX
+}
+
+func NewActivityRepository(db DBTX) *ActivityRepository {
+	return &ActivityRepository{db: db}
+}
+
+func (r *ActivityRepository) Log(ctx context.Context, userID int, description string) error {
+	query := `INSERT INTO user_activity_logs (user_id, description) VALUES ($1, $2)`
+	if _, err := r.db.Exec(ctx, query, userID, description); err != nil {
+		return fmt.Errorf("failed to log activity: %w", err)
+	}
+	return nil
+}
+
+func (r *ActivityRepository) ListByUser(ctx context.Context, userID int) ([]model.Activity, error) {
+	query := `SELECT id, user_id, description, created_at FROM user_activity_logs WHERE user_id = $1 ORDER BY created_at DESC, id DESC`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activities := []model.Activity{}
+	for rows.Next() {
+		var activity model.Activity
+		if err := rows.Scan(&activity.ID, &activity.UserID, &activity.Description, &activity.CreatedAt); err != nil {
+			return nil, err
+		}
+		activities = append(activities, activity)
+	}
+	return activities, rows.Err()
+}
@@ -0,0 +1,216 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository"
+)
+
+// CommentRepository is the pgx implementation of repository.CommentRepository
+type CommentRepository struct {
+	db DBTX
+}
+
+func NewCommentRepository(db DBTX) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+// aggregated reactions of the comment aliased c, as a JSON object of counts by name
+const commentReactions = `(
+	SELECT COALESCE(jsonb_object_agg(reaction, n), '{}')
+	FROM (SELECT reaction, COUNT(*) AS n FROM comment_reactions WHERE comment_id = c.id GROUP BY reaction) r
+)`
+
+const commentColumns = `c.id, c.content, c.post_id, c.parent_id, c.author_id, c.depth, c.version, c.like_count, c.reply_count, ` +
+	commentReactions + `, c.created_at, c.edited_at`
+
+func scanComment(row pgx.Row, dest ...any) (*model.Comment, error) {
+	var comment model.Comment
+	fields := []any{
+		&comment.ID, &comment.Content, &comment.PostID, &comment.ParentID, &comment.AuthorID, &comment.Depth,
+		&comment.Version, &comment.LikeCount, &comment.ReplyCount, &comment.Reactions, &comment.CreatedAt, &comment.EditedAt,
+	}
+	if err := row.Scan(append(fields, dest...)...); err != nil {
+		return nil, err
+	}
+	comment.Edited = comment.EditedAt != nil
+	return &comment, nil
+}
+
+func (r *CommentRepository) Create(ctx context.Context, comment *model.Comment) error {
+	query := `INSERT INTO comments AS c (content, post_id, parent_id, author_id, depth) VALUES ($1, $2, $3, $4, $5) RETURNING ` + commentColumns
+	created, err := scanComment(r.db.QueryRow(ctx, query, comment.Content, comment.PostID, comment.ParentID, comment.AuthorID, comment.Depth))
+	if err != nil {
+		return err
+	}
+	*comment = *created
+	return nil
+}
+
+func (r *CommentRepository) GetByID(ctx context.Context, id int) (*model.Comment, error) {
+	comment, err := scanComment(r.db.QueryRow(ctx, `SELECT `+commentColumns+` FROM comments c WHERE c.id = $1`, id))
+	return comment, err
+}
+
+func (r *CommentRepository) GetDetail(ctx context.Context, id int) (*model.CommentDetail, error) {
+	var detail model.CommentDetail
+	query := `
+		SELECT ` + commentColumns + `, p.content AS post_title, u.full_name AS author_name
+		FROM comments c
+		JOIN posts p ON c.post_id = p.id
+		JOIN users u ON c.author_id = u.id
+		WHERE c.id = $1`
+	comment, err := scanComment(r.db.QueryRow(ctx, query, id), &detail.PostTitle, &detail.AuthorName)
+	if err != nil {
+		return nil, err
+	}
+	detail.Comment = *comment
+	return &detail, nil
+}
+
+func (r *CommentRepository) List(ctx context.Context, filter repository.CommentFilter) ([]model.PostComment, error) {
+	key, desc := "c.created_at", filter.Order != repository.OrderOldest
+	if filter.Order == repository.OrderMostLiked {
+		key = "c.like_count"
+	}
+
+	var k keyset
+	if filter.ParentID != 0 {
+		k.where("c.parent_id = " + k.arg(filter.ParentID))
+	} else {
+		k.where("c.post_id = " + k.arg(filter.PostID))
+		k.where("c.parent_id IS NULL")
+	}
+	if after := filter.After; after != nil {
+		if filter.Order == repository.OrderMostLiked {
+			k.after(key, "c.id", desc, after.Count, after.ID)
+		} else {
+			k.after(key, "c.id", desc, after.Time, after.ID)
+		}
+	}
+	query := `SELECT c.id, c.content, c.parent_id, c.depth, c.author_id, u.full_name, c.like_count, c.reply_count, ` + commentReactions + `, c.created_at, c.edited_at
+	          FROM comments c 
+	          JOIN users u ON c.author_id = u.id` + k.clauses(key, "c.id", desc, filter.Limit)
+	rows, err := r.db.Query(ctx, query, k.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []model.PostComment{}
+	for rows.Next() {
+		var comment model.PostComment
+		err := rows.Scan(
+			&comment.ID, &comment.Content, &comment.ParentID, &comment.Depth, &comment.Author.ID, &comment.Author.Name,
+			&comment.LikeCount, &comment.ReplyCount, &comment.Reactions, &comment.CreatedAt, &comment.EditedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		comment.Edited = comment.EditedAt != nil
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+func (r *CommentRepository) CountByPost(ctx context.Context, postID int) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM comments WHERE post_id = $1`, postID).Scan(&count)
+	return count, err
+}
+
+func (r *CommentRepository) Update(ctx context.Context, comment *model.Comment, editorID int) error {
+	// one statement, see PostRepository.Update
+	query := `
+		WITH old AS (
+			SELECT id, content, version, COALESCE(edited_at, created_at) AS published_at
+			FROM comments WHERE id = $1
+			FOR UPDATE
+		), revision AS (
+			INSERT INTO comment_revisions (comment_id, version, content, created_at, replaced_by)
+			SELECT id, version, content, published_at, $3 FROM old
+		)
+		UPDATE comments c
+		SET content = $2, version = old.version + 1, edited_at = now()
+		FROM old
+		WHERE c.id = old.id
+		RETURNING ` + commentColumns
+	updated, err := scanComment(r.db.QueryRow(ctx, query, comment.ID, comment.Content, editorID))
+	if err != nil {
+		return err
+	}
+	*comment = *updated
+	return nil
+}
+
+func (r *CommentRepository) ListRevisions(ctx context.Context, commentID int) ([]model.CommentRevision, error) {
+	query := `SELECT id, comment_id, version, content, created_at, replaced_at, replaced_by
+	          FROM comment_revisions
+	          WHERE comment_id = $1
+	          ORDER BY version`
+	rows, err := r.db.Query(ctx, query, commentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []model.CommentRevision{}
+	for rows.Next() {
+		var rev model.CommentRevision
+		if err := rows.Scan(&rev.ID, &rev.CommentID, &rev.Version, &rev.Content, &rev.CreatedAt, &rev.ReplacedAt, &rev.ReplacedBy); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *CommentRepository) Delete(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM comments WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// like_count follows through the trigger on comment_likes
+func (r *CommentRepository) Like(ctx context.Context, commentID, userID int) (bool, error) {
+	query := `INSERT INTO comment_likes (comment_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	tag, err := r.db.Exec(ctx, query, commentID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *CommentRepository) Unlike(ctx context.Context, commentID, userID int) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM comment_likes WHERE comment_id = $1 AND user_id = $2`, commentID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *CommentRepository) AddReaction(ctx context.Context, commentID, userID int, reaction string) (bool, error) {
+	query := `INSERT INTO comment_reactions (comment_id, user_id, reaction) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+	tag, err := r.db.Exec(ctx, query, commentID, userID, reaction)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *CommentRepository) RemoveReaction(ctx context.Context, commentID, userID int, reaction string) (bool, error) {
+	query := `DELETE FROM comment_reactions WHERE comment_id = $1 AND user_id = $2 AND reaction = $3`
+	tag, err := r.db.Exec(ctx, query, commentID, userID, reaction)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
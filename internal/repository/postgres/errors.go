@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"w3/gc3/internal/repository"
+)
+
+// SQLSTATE codes of the integrity constraint violations translated by translate
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+	checkViolation      = "23514"
+)
+
+// translate maps pgx errors onto the storage agnostic repository errors,
+// keeping the violated constraint's name in the message
+func translate(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.Code {
+	case uniqueViolation:
+		return fmt.Errorf("%w: %s", repository.ErrDuplicate, pgErr.ConstraintName)
+	case foreignKeyViolation:
+		return fmt.Errorf("%w: %s", repository.ErrInvalidReference, pgErr.ConstraintName)
+	case checkViolation:
+		return fmt.Errorf("%w: %s", repository.ErrConstraint, pgErr.ConstraintName)
+	}
+	return err
+}
+
+// translatingDB runs every statement on db and translates its errors, so the
+// repositories never see raw pgx errors
+type translatingDB struct {
+	db DBTX
+}
+
+func (t translatingDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tag, err := t.db.Exec(ctx, sql, args...)
+	return tag, translate(err)
+}
+
+func (t translatingDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := t.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, translate(err)
+	}
+	return translatingRows{rows}, nil
+}
+
+func (t translatingDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return translatingRow{t.db.QueryRow(ctx, sql, args...)}
+}
+
+type translatingRow struct {
+	row pgx.Row
+}
+
+func (r translatingRow) Scan(dest ...any) error {
+	return translate(r.row.Scan(dest...))
+}
+
+type translatingRows struct {
+	pgx.Rows
+}
+
+func (r translatingRows) Scan(dest ...any) error {
+	return translate(r.Rows.Scan(dest...))
+}
+
+func (r translatingRows) Err() error {
+	return translate(r.Rows.Err())
+}
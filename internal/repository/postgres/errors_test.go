@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"w3/gc3/internal/repository"
+)
+
+func TestTranslate(t *testing.T) {
+	other := errors.New("connection refused")
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"no rows", pgx.ErrNoRows, repository.ErrNotFound},
+		{"wrapped no rows", fmt.Errorf("scan: %w", pgx.ErrNoRows), repository.ErrNotFound},
+		{"unique", &pgconn.PgError{Code: uniqueViolation, ConstraintName: "users_email_key"}, repository.ErrDuplicate},
+		{"foreign key", &pgconn.PgError{Code: foreignKeyViolation, ConstraintName: "fk_post_comments"}, repository.ErrInvalidReference},
+		{"check", &pgconn.PgError{Code: checkViolation, ConstraintName: "chk_follows_self"}, repository.ErrConstraint},
+		{"other postgres error", &pgconn.PgError{Code: "42P01"}, nil},
+		{"other error", other, other},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := translate(tt.err)
+			if tt.want == nil {
+				if got != tt.err {
+					t.Errorf("translate(%v) = %v, want it unchanged", tt.err, got)
+				}
+				return
+			}
+			if !errors.Is(got, tt.want) {
+				t.Errorf("translate(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+
+	if translate(nil) != nil {
+		t.Error("translate(nil) is not nil")
+	}
+}
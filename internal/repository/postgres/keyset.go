@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"strconv"
+	"strings"
+)
+
+// keyset builds the WHERE, ORDER BY and LIMIT clauses of a paginated query
+// ordered by (key, id), together with their positional arguments
+type keyset struct {
+	conds []string
+	args  []any
+}
+
+// arg registers v and returns its placeholder
+func (k *keyset) arg(v any) string {
+	k.args = append(k.args, v)
+	return "$" + strconv.Itoa(len(k.args))
+}
+
+func (k *keyset) where(cond string) {
+	k.conds = append(k.conds, cond)
+}
+
+// after restricts the rows to those following the (key, id) position
+func (k *keyset) after(key, id string, desc bool, keyValue any, idValue int) {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	k.where("(" + key + ", " + id + ") " + op + " (" + k.arg(keyValue) + ", " + k.arg(idValue) + ")")
+}
+
+// clauses returns everything that follows the FROM clause
+func (k *keyset) clauses(key, id string, desc bool, limit int) string {
+	var b strings.Builder
+	if len(k.conds) > 0 {
+		b.WriteString(" WHERE " + strings.Join(k.conds, " AND "))
+	}
+	dir := " ASC"
+	if desc {
+		dir = " DESC"
+	}
+	b.WriteString(" ORDER BY " + key + dir + ", " + id + dir)
+	b.WriteString(" LIMIT " + k.arg(limit))
+	return b.String()
+}
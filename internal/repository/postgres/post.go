@@ -0,0 +1,249 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository"
+)
+
+// PostRepository is the pgx implementation of repository.PostRepository
+type PostRepository struct {
+	db DBTX
+}
+
+func NewPostRepository(db DBTX) *PostRepository {
+	return &PostRepository{db: db}
+}
+
+const postColumns = `id, content, image_url, user_id, version, like_count, created_at, edited_at`
+
+func scanPost(row pgx.Row) (*model.Post, error) {
+	var post model.Post
+	err := row.Scan(&post.ID, &post.Content, &post.ImageURL, &post.UserID, &post.Version, &post.LikeCount, &post.CreatedAt, &post.EditedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+func (r *PostRepository) Create(ctx context.Context, post *model.Post) error {
+	query := `INSERT INTO posts (content, image_url, user_id) VALUES ($1, $2, $3) RETURNING ` + postColumns
+	created, err := scanPost(r.db.QueryRow(ctx, query, post.Content, post.ImageURL, post.UserID))
+	if err != nil {
+		return err
+	}
+	*post = *created
+	return nil
+}
+
+func (r *PostRepository) List(ctx context.Context, filter repository.PostFilter) ([]model.Post, error) {
+	desc := filter.Order != repository.OrderOldest
+
+	var k keyset
+	if filter.UserID != 0 {
+		k.where("user_id = " + k.arg(filter.UserID))
+	}
+	k.where("EXISTS (SELECT 1 FROM users u WHERE u.id = posts.user_id AND u.deleted_at IS NULL)")
+	if filter.After != nil {
+		k.after("created_at", "id", desc, filter.After.Time, filter.After.ID)
+	}
+	query := `SELECT ` + postColumns + ` FROM posts` + k.clauses("created_at", "id", desc, filter.Limit)
+
+	rows, err := r.db.Query(ctx, query, k.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []model.Post{}
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, *post)
+	}
+	return posts, rows.Err()
+}
+
+func (r *PostRepository) Feed(ctx context.Context, filter repository.FeedFilter) ([]model.Post, error) {
+	// Fan-out on read: take the newest page of every followed author through
+	// idx_posts_user_id_created_at_id, then merge them into one page. Each
+	// author contributes at most filter.Limit rows, so the cost grows with the
+	// number of follows rather than with the number of posts they wrote.
+	// Authors who deleted their account drop out of the feed.
+	var k keyset
+	userID := k.arg(filter.UserID)
+	k.where("user_id = a.author_id")
+	if filter.After != nil {
+		k.after("created_at", "id", true, filter.After.Time, filter.After.ID)
+	}
+	perAuthor := k.clauses("created_at", "id", true, filter.Limit)
+	query := `SELECT ` + postColumns + `
+	          FROM (
+	              SELECT f.followee_id AS author_id
+	              FROM follows f
+	              JOIN users u ON f.followee_id = u.id
+	              WHERE f.follower_id = ` + userID + ` AND u.deleted_at IS NULL
+	              UNION ALL SELECT ` + userID + `::int
+	          ) a
+	          CROSS JOIN LATERAL (SELECT ` + postColumns + ` FROM posts` + perAuthor + `) p
+	          ORDER BY created_at DESC, id DESC
+	          LIMIT ` + k.arg(filter.Limit)
+
+	rows, err := r.db.Query(ctx, query, k.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []model.Post{}
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, *post)
+	}
+	return posts, rows.Err()
+}
+
+func (r *PostRepository) GetByID(ctx context.Context, id int) (*model.Post, error) {
+	post, err := scanPost(r.db.QueryRow(ctx, `SELECT `+postColumns+` FROM posts WHERE id = $1`, id))
+	return post, err
+}
+
+func (r *PostRepository) GetActiveByID(ctx context.Context, id int) (*model.Post, error) {
+	query := `SELECT ` + postColumns + ` FROM posts
+	          WHERE id = $1 AND EXISTS (SELECT 1 FROM users u WHERE u.id = posts.user_id AND u.deleted_at IS NULL)`
+	post, err := scanPost(r.db.QueryRow(ctx, query, id))
+	return post, err
+}
+
+func (r *PostRepository) Update(ctx context.Context, post *model.Post) error {
+	// a single statement so the revision always holds what the update replaced:
+	// concurrent edits wait on the row lock and then see the latest version
+	query := `
+		WITH old AS (
+			SELECT id, content, image_url, version, COALESCE(edited_at, created_at) AS published_at
+			FROM posts WHERE id = $1
+			FOR UPDATE
+		), revision AS (
+			INSERT INTO post_revisions (post_id, version, content, image_url, created_at)
+			SELECT id, version, content, image_url, published_at FROM old
+		)
+		UPDATE posts p
+		SET content = $2, image_url = $3, version = old.version + 1, edited_at = now()
+		FROM old
+		WHERE p.id = old.id
+		RETURNING p.id, p.content, p.image_url, p.user_id, p.version, p.like_count, p.created_at, p.edited_at`
+	updated, err := scanPost(r.db.QueryRow(ctx, query, post.ID, post.Content, post.ImageURL))
+	if err != nil {
+		return err
+	}
+	*post = *updated
+	return nil
+}
+
+func (r *PostRepository) ListRevisions(ctx context.Context, postID int) ([]model.PostRevision, error) {
+	query := `SELECT id, post_id, version, content, image_url, created_at, replaced_at
+	          FROM post_revisions
+	          WHERE post_id = $1
+	          ORDER BY version`
+	rows, err := r.db.Query(ctx, query, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []model.PostRevision{}
+	for rows.Next() {
+		var rev model.PostRevision
+		if err := rows.Scan(&rev.ID, &rev.PostID, &rev.Version, &rev.Content, &rev.ImageURL, &rev.CreatedAt, &rev.ReplacedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *PostRepository) Delete(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM posts WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// like_count follows through the trigger on post_likes
+func (r *PostRepository) Like(ctx context.Context, postID, userID int) (bool, error) {
+	query := `INSERT INTO post_likes (post_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	tag, err := r.db.Exec(ctx, query, postID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *PostRepository) Unlike(ctx context.Context, postID, userID int) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM post_likes WHERE post_id = $1 AND user_id = $2`, postID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *PostRepository) LikedBy(ctx context.Context, userID int, postIDs []int) (map[int]bool, error) {
+	liked := map[int]bool{}
+	if len(postIDs) == 0 {
+		return liked, nil
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT post_id FROM post_likes WHERE user_id = $1 AND post_id = ANY($2)`, userID, postIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID int
+		if err := rows.Scan(&postID); err != nil {
+			return nil, err
+		}
+		liked[postID] = true
+	}
+	return liked, rows.Err()
+}
+
+func (r *PostRepository) ListLikers(ctx context.Context, filter repository.LikeFilter) ([]model.PostLiker, error) {
+	var k keyset
+	k.where("l.post_id = " + k.arg(filter.PostID))
+	k.where("u.deleted_at IS NULL")
+	if filter.After != nil {
+		k.after("l.created_at", "l.user_id", true, filter.After.Time, filter.After.ID)
+	}
+	query := `SELECT u.id, u.username, u.full_name, l.created_at
+	          FROM post_likes l
+	          JOIN users u ON l.user_id = u.id` + k.clauses("l.created_at", "l.user_id", true, filter.Limit)
+	rows, err := r.db.Query(ctx, query, k.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	likers := []model.PostLiker{}
+	for rows.Next() {
+		var liker model.PostLiker
+		if err := rows.Scan(&liker.UserID, &liker.Username, &liker.FullName, &liker.LikedAt); err != nil {
+			return nil, err
+		}
+		likers = append(likers, liker)
+	}
+	return likers, rows.Err()
+}
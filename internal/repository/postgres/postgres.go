@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"w3/gc3/internal/repository"
+)
+
+// DBTX is satisfied by both *pgxpool.Pool and pgx.Tx
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// NewRepositories returns every pgx backed repository on top of db, with
+// their errors translated into the repository package's errors
+func NewRepositories(db DBTX) repository.Repositories {
+	if _, ok := db.(translatingDB); !ok {
+		db = translatingDB{db: db}
+	}
+	return repository.Repositories{
+		Users:         NewUserRepository(db),
+		Posts:         NewPostRepository(db),
+		Comments:      NewCommentRepository(db),
+		Activities:    NewActivityRepository(db),
+		RefreshTokens: NewRefreshTokenRepository(db),
+		Sessions:      NewSessionRepository(db),
+	}
+}
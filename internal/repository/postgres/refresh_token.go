@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository"
+)
+
+// RefreshTokenRepository is the pgx implementation of repository.RefreshTokenRepository
+type RefreshTokenRepository struct {
+	db DBTX
+}
+
+func NewRefreshTokenRepository(db DBTX) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *model.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at) VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+	return r.db.QueryRow(ctx, query, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt).Scan(&token.ID, &token.CreatedAt)
+}
+
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, hash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	query := `SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+	          FROM refresh_tokens WHERE token_hash = $1 FOR UPDATE`
+	err := r.db.QueryRow(ctx, query, hash).Scan(
+		&token.ID, &token.UserID, &token.FamilyID, &token.TokenHash,
+		&token.ExpiresAt, &token.UsedAt, &token.RevokedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, `UPDATE refresh_tokens SET used_at = now() WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = now() WHERE family_id = $1 AND revoked_at IS NULL`
+	_, err := r.db.Exec(ctx, query, familyID)
+	return err
+}
+
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int) error {
+	query := `UPDATE refresh_tokens SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`
+	_, err := r.db.Exec(ctx, query, userID)
+	return err
+}
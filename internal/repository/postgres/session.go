@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"w3/gc3/internal/repository"
+)
+
+// SessionRepository is the pgx implementation of repository.SessionRepository
+type SessionRepository struct {
+	db DBTX
+}
+
+func NewSessionRepository(db DBTX) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) RevokeAccessToken(ctx context.Context, jti string, userID int, expiresAt time.Time) error {
+	query := `INSERT INTO revoked_tokens (jti, user_id, expires_at) VALUES ($1, $2, $3) ON CONFLICT (jti) DO NOTHING`
+	_, err := r.db.Exec(ctx, query, jti, userID, expiresAt)
+	return err
+}
+
+func (r *SessionRepository) RevokeAll(ctx context.Context, userID int) error {
+	tag, err := r.db.Exec(ctx, `UPDATE users SET token_version = token_version + 1 WHERE id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SessionRepository) TokenVersion(ctx context.Context, userID int) (int, error) {
+	var version int
+	err := r.db.QueryRow(ctx, `SELECT token_version FROM users WHERE id = $1`, userID).Scan(&version)
+	return version, err
+}
+
+func (r *SessionRepository) IsRevoked(ctx context.Context, jti string, userID int, tokenVersion int) (bool, error) {
+	// a single round trip, this runs on every authenticated request
+	query := `
+		SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)
+		    OR NOT EXISTS (SELECT 1 FROM users WHERE id = $2 AND token_version = $3)`
+	var revoked bool
+	err := r.db.QueryRow(ctx, query, jti, userID, tokenVersion).Scan(&revoked)
+	return revoked, err
+}
+
+func (r *SessionRepository) PurgeExpired(ctx context.Context) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at < now()`)
+	return err
+}
@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"w3/gc3/internal/repository"
+)
+
+// TxBeginner is satisfied by *pgxpool.Pool, *pgx.Conn and pgx.Tx (as a savepoint)
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// UnitOfWork is the pgx implementation of repository.UnitOfWork
+type UnitOfWork struct {
+	db TxBeginner
+}
+
+func NewUnitOfWork(db TxBeginner) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+func (u *UnitOfWork) Do(ctx context.Context, fn func(repos repository.Repositories) error) error {
+	return pgx.BeginFunc(ctx, u.db, func(tx pgx.Tx) error {
+		return fn(NewRepositories(tx))
+	})
+}
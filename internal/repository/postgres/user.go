@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository"
+)
+
+// UserRepository is the pgx implementation of repository.UserRepository
+type UserRepository struct {
+	db DBTX
+}
+
+func NewUserRepository(db DBTX) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
+	query := `INSERT INTO users (full_name, email, username, password, age) VALUES ($1, $2, $3, $4, $5) RETURNING id, role, created_at`
+	err := r.db.QueryRow(ctx, query, user.FullName, user.Email, user.Username, user.Password, user.Age).Scan(&user.ID, &user.Role, &user.CreatedAt)
+	return err
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id int) (*model.User, error) {
+	return r.getOne(ctx, `WHERE id = $1`, id)
+}
+
+func (r *UserRepository) GetActiveByID(ctx context.Context, id int) (*model.User, error) {
+	return r.getOne(ctx, `WHERE id = $1 AND deleted_at IS NULL`, id)
+}
+
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	return r.getOne(ctx, `WHERE email = $1`, email)
+}
+
+func (r *UserRepository) List(ctx context.Context) ([]model.User, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+userColumns+` FROM users ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []model.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	return users, rows.Err()
+}
+
+func (r *UserRepository) GetProfile(ctx context.Context, username string) (*model.UserProfile, error) {
+	query := `SELECT u.id, u.username, u.full_name, u.bio, u.avatar_url,
+	                 (SELECT count(*) FROM posts p WHERE p.user_id = u.id),
+	                 u.follower_count, u.following_count, u.created_at
+	          FROM users u
+	          WHERE u.username = $1 AND u.deleted_at IS NULL`
+	var profile model.UserProfile
+	err := r.db.QueryRow(ctx, query, username).Scan(
+		&profile.ID, &profile.Username, &profile.FullName, &profile.Bio, &profile.AvatarURL,
+		&profile.PostCount, &profile.FollowerCount, &profile.FollowingCount, &profile.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *UserRepository) UpdateProfile(ctx context.Context, user *model.User) error {
+	return r.exec(ctx,
+		`UPDATE users SET full_name = $2, bio = $3, avatar_url = $4 WHERE id = $1`,
+		user.ID, user.FullName, user.Bio, user.AvatarURL,
+	)
+}
+
+func (r *UserRepository) UpdateRole(ctx context.Context, id int, role string) error {
+	return r.exec(ctx, `UPDATE users SET role = $2 WHERE id = $1`, id, role)
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id int, hash string) error {
+	return r.exec(ctx, `UPDATE users SET password = $2 WHERE id = $1`, id, hash)
+}
+
+func (r *UserRepository) SoftDelete(ctx context.Context, id int) error {
+	return r.exec(ctx, `UPDATE users SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL`, id)
+}
+
+func (r *UserRepository) Restore(ctx context.Context, id int) error {
+	return r.exec(ctx, `UPDATE users SET deleted_at = NULL WHERE id = $1`, id)
+}
+
+func (r *UserRepository) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	// posts, comments, logs and tokens go with them through ON DELETE CASCADE
+	tag, err := r.db.Exec(ctx, `DELETE FROM users WHERE deleted_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id int) error {
+	// posts, comments, logs and tokens go with it through ON DELETE CASCADE
+	return r.exec(ctx, `DELETE FROM users WHERE id = $1`, id)
+}
+
+func (r *UserRepository) Follow(ctx context.Context, followerID, followeeID int) (bool, error) {
+	// the counts on users are kept up to date by trg_count_follows
+	tag, err := r.db.Exec(ctx,
+		`INSERT INTO follows (follower_id, followee_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		followerID, followeeID,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *UserRepository) Unfollow(ctx context.Context, followerID, followeeID int) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2`, followerID, followeeID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *UserRepository) ListFollowers(ctx context.Context, filter repository.FollowFilter) ([]model.Follow, error) {
+	return r.listFollows(ctx, filter, "f.followee_id", "f.follower_id")
+}
+
+func (r *UserRepository) ListFollowing(ctx context.Context, filter repository.FollowFilter) ([]model.Follow, error) {
+	return r.listFollows(ctx, filter, "f.follower_id", "f.followee_id")
+}
+
+// listFollows pages through the follows whose userCol is filter.UserID,
+// returning the users in otherCol
+func (r *UserRepository) listFollows(ctx context.Context, filter repository.FollowFilter, userCol, otherCol string) ([]model.Follow, error) {
+	var k keyset
+	k.where(userCol + " = " + k.arg(filter.UserID))
+	k.where("u.deleted_at IS NULL")
+	if filter.After != nil {
+		k.after("f.created_at", otherCol, true, filter.After.Time, filter.After.ID)
+	}
+	query := `SELECT u.id, u.username, u.full_name, f.created_at
+	          FROM follows f
+	          JOIN users u ON ` + otherCol + ` = u.id` + k.clauses("f.created_at", otherCol, true, filter.Limit)
+	rows, err := r.db.Query(ctx, query, k.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	follows := []model.Follow{}
+	for rows.Next() {
+		var follow model.Follow
+		if err := rows.Scan(&follow.UserID, &follow.Username, &follow.FullName, &follow.FollowedAt); err != nil {
+			return nil, err
+		}
+		follows = append(follows, follow)
+	}
+	return follows, rows.Err()
+}
+
+// exec runs a statement on a single user, ErrNotFound when it matched none
+func (r *UserRepository) exec(ctx context.Context, query string, args ...any) error {
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+const userColumns = `id, full_name, email, username, password, age, bio, avatar_url, role, created_at, deleted_at, follower_count, following_count`
+
+func scanUser(row pgx.Row) (*model.User, error) {
+	var user model.User
+	err := row.Scan(
+		&user.ID, &user.FullName, &user.Email, &user.Username, &user.Password, &user.Age, &user.Bio, &user.AvatarURL, &user.Role, &user.CreatedAt, &user.DeletedAt,
+		&user.FollowerCount, &user.FollowingCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) getOne(ctx context.Context, where string, args ...any) (*model.User, error) {
+	return scanUser(r.db.QueryRow(ctx, `SELECT `+userColumns+` FROM users `+where, args...))
+}
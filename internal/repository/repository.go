@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/pagination"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicate is returned when a record violates a uniqueness rule
+	ErrDuplicate = errors.New("record already exists")
+	// ErrInvalidReference is returned when a record refers to another record
+	// that does not exist, such as a comment on a missing post
+	ErrInvalidReference = errors.New("referenced record does not exist")
+	// ErrConstraint is returned when a record breaks any other rule of the
+	// store, such as a user following themselves
+	ErrConstraint = errors.New("record violates a constraint")
+)
+
+// UserRepository stores user accounts
+type UserRepository interface {
+	// Create inserts the user and sets its ID and CreatedAt
+	Create(ctx context.Context, user *model.User) error
+	GetByID(ctx context.Context, id int) (*model.User, error)
+	// GetActiveByID is GetByID for the public read paths, ErrNotFound for
+	// deleted accounts
+	GetActiveByID(ctx context.Context, id int) (*model.User, error)
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	List(ctx context.Context) ([]model.User, error)
+	// GetProfile returns the public profile of the user with this username,
+	// ErrNotFound for deleted accounts
+	GetProfile(ctx context.Context, username string) (*model.UserProfile, error)
+	// UpdateProfile saves the user's FullName, Bio and AvatarURL
+	UpdateProfile(ctx context.Context, user *model.User) error
+	UpdateRole(ctx context.Context, id int, role string) error
+	// UpdatePassword replaces the user's password hash
+	UpdatePassword(ctx context.Context, id int, hash string) error
+	// SoftDelete marks the user as deleted, keeping everything they own until
+	// the account is purged
+	SoftDelete(ctx context.Context, id int) error
+	// Restore undoes SoftDelete
+	Restore(ctx context.Context, id int) error
+	// PurgeDeleted removes the users soft-deleted before the given time
+	// together with everything they own, returning how many were removed
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error)
+	// Delete removes the user together with everything they own
+	Delete(ctx context.Context, id int) error
+	// Follow records that the follower follows the followee, reporting false if
+	// they already did
+	Follow(ctx context.Context, followerID, followeeID int) (bool, error)
+	// Unfollow removes the follow, reporting false if there was none
+	Unfollow(ctx context.Context, followerID, followeeID int) (bool, error)
+	// ListFollowers returns the users following filter.UserID, leaving out
+	// deleted accounts
+	ListFollowers(ctx context.Context, filter FollowFilter) ([]model.Follow, error)
+	// ListFollowing returns the users filter.UserID follows, leaving out
+	// deleted accounts
+	ListFollowing(ctx context.Context, filter FollowFilter) ([]model.Follow, error)
+}
+
+// Orderings of paginated listings, the id breaks ties
+const (
+	OrderNewest    = "newest"     // by creation time, newest first
+	OrderOldest    = "oldest"     // by creation time, oldest first
+	OrderMostLiked = "most_liked" // by like count, most liked first
+)
+
+// PostFilter selects a page of posts
+type PostFilter struct {
+	UserID int    // only the posts of this user when not 0
+	Order  string // OrderNewest or OrderOldest
+	Limit  int
+	After  *pagination.Cursor // start after this post, nil for the first page
+}
+
+// FeedFilter selects a page of the home feed of a user: their own posts and
+// the posts of the users they follow, newest first
+type FeedFilter struct {
+	UserID int
+	Limit  int
+	After  *pagination.Cursor // start after this post, nil for the first page
+}
+
+// LikeFilter selects a page of the users who liked a post, latest first
+type LikeFilter struct {
+	PostID int
+	Limit  int
+	After  *pagination.Cursor // start after this like, nil for the first page
+}
+
+// FollowFilter selects a page of the followers or followed users of a user,
+// latest follow first
+type FollowFilter struct {
+	UserID int
+	Limit  int
+	After  *pagination.Cursor // start after this follow, nil for the first page
+}
+
+// PostRepository stores posts
+type PostRepository interface {
+	// Create inserts the post and sets its ID
+	Create(ctx context.Context, post *model.Post) error
+	// List returns up to filter.Limit posts in filter.Order, leaving out the
+	// posts of deleted accounts
+	List(ctx context.Context, filter PostFilter) ([]model.Post, error)
+	// Feed returns up to filter.Limit posts of the home feed of filter.UserID,
+	// leaving out the posts of deleted accounts
+	Feed(ctx context.Context, filter FeedFilter) ([]model.Post, error)
+	GetByID(ctx context.Context, id int) (*model.Post, error)
+	// GetActiveByID is GetByID for the public read paths, ErrNotFound for the
+	// posts of deleted accounts
+	GetActiveByID(ctx context.Context, id int) (*model.Post, error)
+	// Update saves the post's Content and ImageURL, keeping the version it
+	// replaces as a revision, and sets Version and EditedAt
+	Update(ctx context.Context, post *model.Post) error
+	// ListRevisions returns the previous versions of the post, oldest first
+	ListRevisions(ctx context.Context, postID int) ([]model.PostRevision, error)
+	Delete(ctx context.Context, id int) error
+	// Like records that the user likes the post, reporting false if they already did
+	Like(ctx context.Context, postID, userID int) (bool, error)
+	// Unlike removes the user's like, reporting false if there was none
+	Unlike(ctx context.Context, postID, userID int) (bool, error)
+	// LikedBy returns which of the posts the user likes
+	LikedBy(ctx context.Context, userID int, postIDs []int) (map[int]bool, error)
+	// ListLikers returns the users who liked filter.PostID, leaving out
+	// deleted accounts
+	ListLikers(ctx context.Context, filter LikeFilter) ([]model.PostLiker, error)
+}
+
+// CommentFilter selects a page of the top-level comments of a post, or of
+// the replies to a comment
+type CommentFilter struct {
+	PostID   int
+	ParentID int    // list the replies to this comment instead when not 0
+	Order    string // OrderOldest, OrderNewest or OrderMostLiked
+	Limit    int
+	After    *pagination.Cursor // start after this comment, nil for the first page
+}
+
+// PostCursor returns the position of post in a listing sorted by order
+func PostCursor(order string) func(model.Post) pagination.Cursor {
+	return func(post model.Post) pagination.Cursor {
+		return pagination.Cursor{Order: order, Time: post.CreatedAt, ID: post.ID}
+	}
+}
+
+// LikerCursor returns the position of liker in the likes of a post
+func LikerCursor(liker model.PostLiker) pagination.Cursor {
+	return pagination.Cursor{Order: OrderNewest, Time: liker.LikedAt, ID: liker.UserID}
+}
+
+// FollowCursor returns the position of follow in a followers or following listing
+func FollowCursor(follow model.Follow) pagination.Cursor {
+	return pagination.Cursor{Order: OrderNewest, Time: follow.FollowedAt, ID: follow.UserID}
+}
+
+// CommentCursor returns the position of comment in a listing sorted by order
+func CommentCursor(order string) func(model.PostComment) pagination.Cursor {
+	return func(comment model.PostComment) pagination.Cursor {
+		if order == OrderMostLiked {
+			return pagination.Cursor{Order: order, Count: comment.LikeCount, ID: comment.ID}
+		}
+		return pagination.Cursor{Order: order, Time: comment.CreatedAt, ID: comment.ID}
+	}
+}
+
+// CommentRepository stores comments on posts, every comment it returns
+// carries its aggregated reactions
+type CommentRepository interface {
+	// Create inserts the comment and sets its ID; Depth must already be set
+	// for replies
+	Create(ctx context.Context, comment *model.Comment) error
+	GetByID(ctx context.Context, id int) (*model.Comment, error)
+	GetDetail(ctx context.Context, id int) (*model.CommentDetail, error)
+	// List returns up to filter.Limit comments in filter.Order
+	List(ctx context.Context, filter CommentFilter) ([]model.PostComment, error)
+	// CountByPost counts every comment of the post, replies included
+	CountByPost(ctx context.Context, postID int) (int, error)
+	// Update saves the comment's Content, keeping the version it replaces as a
+	// revision attributed to editorID, and sets Version and EditedAt
+	Update(ctx context.Context, comment *model.Comment, editorID int) error
+	// ListRevisions returns the previous versions of the comment, oldest first
+	ListRevisions(ctx context.Context, commentID int) ([]model.CommentRevision, error)
+	Delete(ctx context.Context, id int) error
+	// Like records that the user likes the comment, reporting false if they already did
+	Like(ctx context.Context, commentID, userID int) (bool, error)
+	// Unlike removes the user's like, reporting false if there was none
+	Unlike(ctx context.Context, commentID, userID int) (bool, error)
+	// AddReaction records the user's reaction, reporting false if they already reacted so
+	AddReaction(ctx context.Context, commentID, userID int, reaction string) (bool, error)
+	// RemoveReaction removes the user's reaction, reporting false if there was none
+	RemoveReaction(ctx context.Context, commentID, userID int, reaction string) (bool, error)
+}
+
+// ActivityRepository stores the user activity logs
+type ActivityRepository interface {
+	Log(ctx context.Context, userID int, description string) error
+	ListByUser(ctx context.Context, userID int) ([]model.Activity, error)
+}
+
+// RefreshTokenRepository stores refresh tokens by the hash of their value
+type RefreshTokenRepository interface {
+	// Create inserts the token and sets its ID and CreatedAt
+	Create(ctx context.Context, token *model.RefreshToken) error
+	// GetByHash also locks the token until the surrounding transaction ends
+	GetByHash(ctx context.Context, hash string) (*model.RefreshToken, error)
+	MarkUsed(ctx context.Context, id int) error
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID int) error
+}
+
+// SessionRepository tracks the server-side revocation of access tokens, either
+// one at a time by jti or all of a user's tokens at once through a version number
+type SessionRepository interface {
+	// RevokeAccessToken denylists jti until the token would have expired anyway
+	RevokeAccessToken(ctx context.Context, jti string, userID int, expiresAt time.Time) error
+	// RevokeAll bumps the user's token version, invalidating every token issued so far
+	RevokeAll(ctx context.Context, userID int) error
+	TokenVersion(ctx context.Context, userID int) (int, error)
+	// IsRevoked reports whether the token was logged out, predates the user's
+	// current token version or belongs to a user that no longer exists
+	IsRevoked(ctx context.Context, jti string, userID int, tokenVersion int) (bool, error)
+	// PurgeExpired forgets denylisted tokens that have expired on their own
+	PurgeExpired(ctx context.Context) error
+}
+
+// Repositories bundles every repository of one storage backend
+type Repositories struct {
+	Users         UserRepository
+	Posts         PostRepository
+	Comments      CommentRepository
+	Activities    ActivityRepository
+	RefreshTokens RefreshTokenRepository
+	Sessions      SessionRepository
+}
+
+// UnitOfWork runs several repository calls atomically
+type UnitOfWork interface {
+	// Do hands fn repositories bound to a single transaction, committing it
+	// when fn returns nil and rolling it back otherwise
+	Do(ctx context.Context, fn func(repos Repositories) error) error
+}
+
+// Toggle adds or removes one of userID's likes, reactions or follows in a
+// single unit of work. load reads the target, failing when it does not exist,
+// toggle makes the change and reports whether there was one to make, in which
+// case description is logged as an activity of userID. load then runs again so
+// the caller gets the target's up to date counts.
+func Toggle(ctx context.Context, uow UnitOfWork, userID int, description string,
+	load func(repos Repositories) error, toggle func(repos Repositories) (bool, error)) error {
+	return uow.Do(ctx, func(repos Repositories) error {
+		if err := load(repos); err != nil {
+			return err
+		}
+		changed, err := toggle(repos)
+		if err != nil {
+			return err
+		}
+		if changed {
+			if err := repos.Activities.Log(ctx, userID, description); err != nil {
+				return err
+			}
+		}
+		return load(repos)
+	})
+}
@@ -0,0 +1,110 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"w3/gc3/config/settings"
+)
+
+var (
+	// ErrRetiredKey is returned for tokens signed with a key that has been rotated out
+	ErrRetiredKey = errors.New("token signed with a retired key")
+	// ErrUnknownKey is returned for tokens whose kid is missing or not configured
+	ErrUnknownKey = errors.New("token signed with an unknown key")
+)
+
+// Claims are the claims of an access token
+type Claims struct {
+	UserID    int      `json:"user_id"`
+	Username  string   `json:"username"`
+	Roles     []string `json:"roles"`
+	Version   int      `json:"ver"`           // token version of the user, see logout-all
+	SessionID string   `json:"sid,omitempty"` // refresh token family the token was issued with
+	jwt.RegisteredClaims
+}
+
+// Service signs and verifies the JWTs of the application. Every token carries
+// the id of its key in the `kid` header so keys can be rotated: tokens signed
+// with any active key verify, new ones are signed with the current signing key.
+type Service struct {
+	signingKeyID string
+	keys         map[string][]byte
+	retired      map[string]bool
+	ttl          time.Duration
+	refreshTTL   time.Duration
+}
+
+func NewService(cfg settings.JWTConfig) (*Service, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	signing, _ := cfg.SigningKey()
+	s := &Service{
+		signingKeyID: signing.ID,
+		keys:         map[string][]byte{},
+		retired:      map[string]bool{},
+		ttl:          time.Duration(cfg.TTL),
+		refreshTTL:   time.Duration(cfg.RefreshTTL),
+	}
+	for _, key := range cfg.Keys {
+		if key.Retired {
+			s.retired[key.ID] = true
+			continue
+		}
+		s.keys[key.ID] = []byte(key.Secret)
+	}
+	return s, nil
+}
+
+// TTL is how long newly issued access tokens stay valid
+func (s *Service) TTL() time.Duration {
+	return s.ttl
+}
+
+// RefreshTTL is how long newly issued refresh tokens stay valid
+func (s *Service) RefreshTTL() time.Duration {
+	return s.refreshTTL
+}
+
+// Sign returns the signed token for claims using the current signing key
+func (s *Service) Sign(claims *Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = s.signingKeyID
+	return token.SignedString(s.keys[s.signingKeyID])
+}
+
+// Parse verifies tokenString and returns its claims
+func (s *Service) Parse(tokenString string) (*Claims, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+
+	// key errors stay reachable through errors.Is, jwt.ValidationError unwraps to them
+	claims := &Claims{}
+	token, err := parser.ParseWithClaims(tokenString, claims, s.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if claims.ID == "" || claims.UserID == 0 || claims.ExpiresAt == nil {
+		return nil, errors.New("token is missing required claims")
+	}
+	return claims, nil
+}
+
+func (s *Service) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if s.retired[kid] {
+		return nil, ErrRetiredKey
+	}
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
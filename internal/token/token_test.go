@@ -0,0 +1,108 @@
+package token
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"w3/gc3/config/settings"
+)
+
+func newService(t *testing.T, signingKeyID string, keys ...settings.SigningKey) *Service {
+	t.Helper()
+	s, err := NewService(settings.JWTConfig{
+		SigningKeyID: signingKeyID,
+		Keys:         keys,
+		TTL:          settings.Duration(time.Minute),
+		RefreshTTL:   settings.Duration(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func key(id string) settings.SigningKey {
+	return settings.SigningKey{ID: id, Secret: strings.Repeat(id, 32)}
+}
+
+func sign(t *testing.T, s *Service) string {
+	t.Helper()
+	token, err := s.Sign(&Claims{
+		UserID: 1,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "jti",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestRotation(t *testing.T) {
+	old := newService(t, "k1", key("k1"))
+	oldToken := sign(t, old)
+
+	// k2 becomes the signing key, k1 still verifies until its tokens expire
+	rotated := newService(t, "k2", key("k1"), key("k2"))
+	newToken := sign(t, rotated)
+	for name, token := range map[string]string{"k1": oldToken, "k2": newToken} {
+		claims, err := rotated.Parse(token)
+		if err != nil || claims.UserID != 1 {
+			t.Errorf("token signed with %s: %v", name, err)
+		}
+	}
+	if _, err := old.Parse(newToken); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("k2 token on a service without k2: %v, want ErrUnknownKey", err)
+	}
+
+	// once k1 is retired its tokens are rejected outright
+	retired := newService(t, "k2", settings.SigningKey{ID: "k1", Retired: true}, key("k2"))
+	if _, err := retired.Parse(oldToken); !errors.Is(err, ErrRetiredKey) {
+		t.Errorf("k1 token after retiring k1: %v, want ErrRetiredKey", err)
+	}
+	if _, err := retired.Parse(newToken); err != nil {
+		t.Errorf("k2 token after retiring k1: %v", err)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	s := newService(t, "k1", key("k1"))
+
+	// same kid, different secret
+	forged := sign(t, newService(t, "k1", settings.SigningKey{ID: "k1", Secret: strings.Repeat("x", 32)}))
+	if _, err := s.Parse(forged); err == nil {
+		t.Error("token signed with another secret verified")
+	}
+
+	noKid := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{UserID: 1})
+	signed, _ := noKid.SignedString([]byte(strings.Repeat("k1", 32)))
+	if _, err := s.Parse(signed); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("token without kid: %v, want ErrUnknownKey", err)
+	}
+
+	if _, err := s.Parse(sign(t, s) + "x"); err == nil {
+		t.Error("tampered token verified")
+	}
+}
+
+func TestNewServiceRejects(t *testing.T) {
+	tests := map[string]settings.JWTConfig{
+		"no keys":            {},
+		"short secret":       {Keys: []settings.SigningKey{{ID: "k1", Secret: "short"}}},
+		"retired signing":    {SigningKeyID: "k1", Keys: []settings.SigningKey{{ID: "k1", Retired: true}}},
+		"unknown signing id": {SigningKeyID: "k9", Keys: []settings.SigningKey{key("k1")}},
+	}
+	for name, cfg := range tests {
+		cfg.TTL = settings.Duration(time.Minute)
+		cfg.RefreshTTL = settings.Duration(time.Hour)
+		if _, err := NewService(cfg); err == nil {
+			t.Errorf("%s: NewService succeeded", name)
+		}
+	}
+}
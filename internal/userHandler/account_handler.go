@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"w3/gc3/internal/apperror"
+	"w3/gc3/internal/auth"
+	"w3/gc3/internal/repository"
+	"w3/gc3/internal/token"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ChangePasswordRequest struct
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,password"`
+}
+
+// errWrongPassword is returned from inside a unit of work when the current
+// password does not match
+var errWrongPassword = errors.New("current password is incorrect")
+
+// @Summary Change your password
+// @Description Replace the password of the authenticated user. Every other session is logged out; the response carries fresh tokens for this one.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} LoginResponse "Password changed"
+// @Failure 400 {object} apperror.Response "Invalid input or wrong current password"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /users/me/password [post]
+func (h *UserHandler) ChangePassword(c echo.Context) error {
+	principal, ok := auth.CurrentUser(c)
+	if !ok {
+		return apperror.Unauthorized("not authorized")
+	}
+	userID := principal.UserID
+
+	req := new(ChangePasswordRequest)
+	if err := c.Bind(req); err != nil {
+		return apperror.BadRequest("invalid request body")
+	}
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return apperror.Internal(err, "failed to hash password")
+	}
+	familyID, err := token.NewFamilyID()
+	if err != nil {
+		return apperror.Internal(err, "failed to generate token")
+	}
+
+	// save the password, log every session out and start a new one for this
+	// client in one transaction
+	var resp *LoginResponse
+	ctx := c.Request().Context()
+	err = h.uow.Do(ctx, func(repos repository.Repositories) error {
+		user, err := repos.Users.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+			return errWrongPassword
+		}
+
+		if err := repos.Users.UpdatePassword(ctx, userID, string(hash)); err != nil {
+			return err
+		}
+		if err := repos.Sessions.RevokeAll(ctx, userID); err != nil {
+			return err
+		}
+		if err := repos.RefreshTokens.RevokeAllForUser(ctx, userID); err != nil {
+			return err
+		}
+		if err := repos.Activities.Log(ctx, userID, "User changed their password"); err != nil {
+			return err
+		}
+		resp, err = h.issueTokens(ctx, repos, userID, familyID)
+		return err
+	})
+	if errors.Is(err, errWrongPassword) {
+		return apperror.BadRequest("current password is incorrect")
+	}
+	if err != nil {
+		return apperror.Internal(err, "failed to change password")
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// @Summary Delete your account
+// @Description Schedule the account of the authenticated user for deletion and log out every session. Logging in again before purge_at restores the account; after that it is removed for good together with its posts, comments and activity logs.
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} map[string]interface{} "Account scheduled for deletion"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /users/me [delete]
+func (h *UserHandler) DeleteMe(c echo.Context) error {
+	principal, ok := auth.CurrentUser(c)
+	if !ok {
+		return apperror.Unauthorized("not authorized")
+	}
+	userID := principal.UserID
+
+	// mark the account and log every session out in one transaction
+	ctx := c.Request().Context()
+	err := h.uow.Do(ctx, func(repos repository.Repositories) error {
+		if err := repos.Users.SoftDelete(ctx, userID); err != nil {
+			return err
+		}
+		if err := repos.Sessions.RevokeAll(ctx, userID); err != nil {
+			return err
+		}
+		if err := repos.RefreshTokens.RevokeAllForUser(ctx, userID); err != nil {
+			return err
+		}
+		return repos.Activities.Log(ctx, userID, "User deleted their account")
+	})
+	if err != nil {
+		return apperror.Internal(err, "failed to delete account")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":  "account scheduled for deletion, log in again before purge_at to restore it",
+		"purge_at": time.Now().Add(h.gracePeriod),
+	})
+}
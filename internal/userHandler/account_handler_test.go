@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"testing"
+
+	"w3/gc3/internal/handlertest"
+	"w3/gc3/internal/repository/memory"
+)
+
+func TestChangePassword(t *testing.T) {
+	store := memory.NewStore()
+	handlertest.CreateUser(t, store, "alice")
+	e := newServer(t, store)
+	access, refresh := login(t, e, "alice")
+	_, otherRefresh := login(t, e, "alice") // another session
+
+	resp := handlertest.Do(t, e, "POST", "/users/me/password", `{"current_password":"wrong","new_password":"newsecret123"}`, bearer(access)...)
+	if resp.Code != 400 {
+		t.Fatalf("wrong current password: status %d, want 400: %v", resp.Code, resp.Body)
+	}
+	if resp := handlertest.Do(t, e, "GET", "/users/me", "", bearer(access)...); resp.Code != 200 {
+		t.Fatalf("a failed change logs nobody out: status %d: %v", resp.Code, resp.Body)
+	}
+
+	resp = handlertest.Do(t, e, "POST", "/users/me/password", `{"current_password":"`+handlertest.Password+`","new_password":"newsecret123"}`, bearer(access)...)
+	if resp.Code != 200 {
+		t.Fatalf("change: status %d: %v", resp.Code, resp.Body)
+	}
+	newAccess, newRefresh := resp.Body["access_token"].(string), resp.Body["refresh_token"].(string)
+
+	// every session from before the change is logged out
+	if resp := handlertest.Do(t, e, "GET", "/users/me", "", bearer(access)...); resp.Code != 401 {
+		t.Errorf("old access token: status %d, want 401", resp.Code)
+	}
+	for _, old := range []string{refresh, otherRefresh} {
+		if resp := handlertest.Do(t, e, "POST", "/users/refresh", `{"refresh_token":"`+old+`"}`); resp.Code != 401 {
+			t.Errorf("old refresh token: status %d, want 401", resp.Code)
+		}
+	}
+	// while the one started by the change carries on
+	if resp := handlertest.Do(t, e, "GET", "/users/me", "", bearer(newAccess)...); resp.Code != 200 {
+		t.Errorf("new access token: status %d, want 200: %v", resp.Code, resp.Body)
+	}
+	if resp := handlertest.Do(t, e, "POST", "/users/refresh", `{"refresh_token":"`+newRefresh+`"}`); resp.Code != 200 {
+		t.Errorf("new refresh token: status %d, want 200: %v", resp.Code, resp.Body)
+	}
+
+	if resp := handlertest.Do(t, e, "POST", "/users/login", `{"email":"alice@example.com","password":"`+handlertest.Password+`"}`); resp.Code == 200 {
+		t.Error("the old password still logs in")
+	}
+	if resp := handlertest.Do(t, e, "POST", "/users/login", `{"email":"alice@example.com","password":"newsecret123"}`); resp.Code != 200 {
+		t.Errorf("new password: status %d, want 200: %v", resp.Code, resp.Body)
+	}
+}
+
+func TestDeleteMe(t *testing.T) {
+	store := memory.NewStore()
+	handlertest.CreateUser(t, store, "alice")
+	e := newServer(t, store)
+	access, refresh := login(t, e, "alice")
+
+	resp := handlertest.Do(t, e, "DELETE", "/users/me", "", bearer(access)...)
+	if resp.Code != 200 || resp.Body["purge_at"] == nil {
+		t.Fatalf("delete: status %d: %v", resp.Code, resp.Body)
+	}
+
+	if resp := handlertest.Do(t, e, "GET", "/users/me", "", bearer(access)...); resp.Code != 401 {
+		t.Errorf("access token: status %d, want 401", resp.Code)
+	}
+	if resp := handlertest.Do(t, e, "POST", "/users/refresh", `{"refresh_token":"`+refresh+`"}`); resp.Code != 401 {
+		t.Errorf("refresh token: status %d, want 401", resp.Code)
+	}
+	if resp := handlertest.Do(t, e, "GET", "/users/alice", ""); resp.Code != 404 {
+		t.Errorf("profile of a deleted account: status %d, want 404", resp.Code)
+	}
+}
+
+func TestLoginRestoresDeletedAccount(t *testing.T) {
+	store := memory.NewStore()
+	handlertest.CreateUser(t, store, "alice")
+	e := newServer(t, store)
+	access, _ := login(t, e, "alice")
+	if resp := handlertest.Do(t, e, "DELETE", "/users/me", "", bearer(access)...); resp.Code != 200 {
+		t.Fatalf("delete: status %d: %v", resp.Code, resp.Body)
+	}
+
+	// without a grace period the deleted account only waits for the purger
+	expired := handlertest.NewEcho()
+	expired.POST("/users/login", NewUserHandler(store.Repositories().Users, store, handlertest.NewTokens(t), 0).Login)
+	body := `{"email":"alice@example.com","password":"` + handlertest.Password + `"}`
+	if resp := handlertest.Do(t, expired, "POST", "/users/login", body); resp.Code == 200 {
+		t.Fatal("logged in after the grace period")
+	}
+	if resp := handlertest.Do(t, e, "GET", "/users/alice", ""); resp.Code != 404 {
+		t.Fatalf("a failed login restores nothing: status %d, want 404", resp.Code)
+	}
+
+	// within it logging in restores the account
+	access, _ = login(t, e, "alice")
+	if resp := handlertest.Do(t, e, "GET", "/users/me", "", bearer(access)...); resp.Code != 200 {
+		t.Errorf("me after restore: status %d, want 200: %v", resp.Code, resp.Body)
+	}
+	if resp := handlertest.Do(t, e, "GET", "/users/alice", ""); resp.Code != 200 {
+		t.Errorf("profile after restore: status %d, want 200: %v", resp.Code, resp.Body)
+	}
+}
@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"w3/gc3/internal/apperror"
+	"w3/gc3/internal/auth"
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/pagination"
+	"w3/gc3/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// FollowerListResponse is one page of the users following a user
+type FollowerListResponse struct {
+	Followers     []model.Follow `json:"followers"`
+	FollowerCount int            `json:"follower_count"`
+	NextCursor    string         `json:"next_cursor"` // empty on the last page
+}
+
+// FollowingListResponse is one page of the users a user follows
+type FollowingListResponse struct {
+	Following      []model.Follow `json:"following"`
+	FollowingCount int            `json:"following_count"`
+	NextCursor     string         `json:"next_cursor"` // empty on the last page
+}
+
+// @Summary Follow a user
+// @Description Follow another user, following them again has no effect. Deleted accounts cannot be followed.
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{} "User followed"
+// @Failure 400 {object} apperror.Response "Invalid input or following yourself"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "User not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /users/{id}/follow [post]
+func (h *UserHandler) FollowUser(c echo.Context) error {
+	return h.setFollow(c, true)
+}
+
+// @Summary Unfollow a user
+// @Description Stop following a user, unfollowing them again has no effect
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{} "User unfollowed"
+// @Failure 400 {object} apperror.Response "Invalid input or unfollowing yourself"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "User not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /users/{id}/follow [delete]
+func (h *UserHandler) UnfollowUser(c echo.Context) error {
+	return h.setFollow(c, false)
+}
+
+// @Summary Get the followers of a user
+// @Description Retrieve a page of the users following a user, latest follow first. Pass the returned next_cursor as cursor to get the following page; it is empty on the last page.
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} FollowerListResponse "Page of followers"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "User not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /users/{id}/followers [get]
+func (h *UserHandler) GetFollowers(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("invalid user ID")
+	}
+
+	page, err := pagination.Parse(c.QueryParam("limit"), c.QueryParam("cursor"), repository.OrderNewest)
+	if err != nil {
+		return apperror.BadRequest(err.Error())
+	}
+
+	user, err := h.users.GetActiveByID(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("user not found")
+		}
+		return apperror.Internal(err, "failed to fetch user")
+	}
+
+	// one extra follower tells whether there is a next page
+	followers, err := h.users.ListFollowers(c.Request().Context(), repository.FollowFilter{
+		UserID: user.ID,
+		Limit:  page.Limit + 1,
+		After:  page.After,
+	})
+	if err != nil {
+		return apperror.Internal(err, "failed to fetch followers")
+	}
+
+	followers, next := pagination.Page(followers, page, repository.FollowCursor)
+	return c.JSON(http.StatusOK, FollowerListResponse{
+		Followers:     followers,
+		FollowerCount: user.FollowerCount,
+		NextCursor:    next,
+	})
+}
+
+// @Summary Get the users a user follows
+// @Description Retrieve a page of the users a user follows, latest follow first. Pass the returned next_cursor as cursor to get the following page; it is empty on the last page.
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} FollowingListResponse "Page of followed users"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 404 {object} apperror.Response "User not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /users/{id}/following [get]
+func (h *UserHandler) GetFollowing(c echo.Context) error {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("invalid user ID")
+	}
+
+	page, err := pagination.Parse(c.QueryParam("limit"), c.QueryParam("cursor"), repository.OrderNewest)
+	if err != nil {
+		return apperror.BadRequest(err.Error())
+	}
+
+	user, err := h.users.GetActiveByID(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("user not found")
+		}
+		return apperror.Internal(err, "failed to fetch user")
+	}
+
+	// one extra followed user tells whether there is a next page
+	following, err := h.users.ListFollowing(c.Request().Context(), repository.FollowFilter{
+		UserID: user.ID,
+		Limit:  page.Limit + 1,
+		After:  page.After,
+	})
+	if err != nil {
+		return apperror.Internal(err, "failed to fetch followed users")
+	}
+
+	following, next := pagination.Page(following, page, repository.FollowCursor)
+	return c.JSON(http.StatusOK, FollowingListResponse{
+		Following:      following,
+		FollowingCount: user.FollowingCount,
+		NextCursor:     next,
+	})
+}
+
+// setFollow makes the principal follow or unfollow a user and logs it when
+// something changed
+func (h *UserHandler) setFollow(c echo.Context, follow bool) error {
+	followeeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return apperror.BadRequest("invalid user ID")
+	}
+
+	principal, ok := auth.CurrentUser(c)
+	if !ok {
+		return apperror.Unauthorized("not authorized")
+	}
+	userID := principal.UserID
+	if followeeID == userID {
+		if follow {
+			return apperror.BadRequest("you cannot follow yourself")
+		}
+		return apperror.BadRequest("you cannot unfollow yourself")
+	}
+
+	var followee *model.User
+	ctx := c.Request().Context()
+	load := func(repos repository.Repositories) (err error) {
+		followee, err = repos.Users.GetByID(ctx, followeeID)
+		return err
+	}
+	description := "User followed USER with ID " + strconv.Itoa(followeeID)
+	toggle := func(repos repository.Repositories) (bool, error) {
+		// a deleted account can still be unfollowed but gains no new followers
+		if followee.DeletedAt != nil {
+			return false, repository.ErrNotFound
+		}
+		return repos.Users.Follow(ctx, userID, followeeID)
+	}
+	if !follow {
+		description = "User unfollowed USER with ID " + strconv.Itoa(followeeID)
+		toggle = func(repos repository.Repositories) (bool, error) {
+			return repos.Users.Unfollow(ctx, userID, followeeID)
+		}
+	}
+	err = repository.Toggle(ctx, h.uow, userID, description, load, toggle)
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound("user not found")
+	}
+	if err != nil {
+		return apperror.Internal(err, "failed to update follow")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"following":      follow,
+		"follower_count": followee.FollowerCount,
+	})
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"w3/gc3/internal/handlertest"
+	"w3/gc3/internal/repository/memory"
+)
+
+func TestFollow(t *testing.T) {
+	store := memory.NewStore()
+	alice := handlertest.CreateUser(t, store, "alice")
+	bob := handlertest.CreateUser(t, store, "bob")
+	e := newServer(t, store)
+	bobToken, _ := login(t, e, "bob")
+	path := "/users/" + strconv.Itoa(alice.ID) + "/follow"
+
+	steps := []struct {
+		method    string
+		following bool
+		count     float64
+	}{
+		{"POST", true, 1},
+		{"POST", true, 1}, // following again has no effect
+		{"DELETE", false, 0},
+		{"POST", true, 1},
+	}
+	for _, step := range steps {
+		resp := handlertest.Do(t, e, step.method, path, "", bearer(bobToken)...)
+		if resp.Code != 200 || resp.Body["following"] != step.following || resp.Body["follower_count"] != step.count {
+			t.Fatalf("%s %s: status %d: %v", step.method, path, resp.Code, resp.Body)
+		}
+	}
+
+	resp := handlertest.Do(t, e, "GET", "/users/"+strconv.Itoa(alice.ID)+"/followers", "", bearer(bobToken)...)
+	if resp.Code != 200 || resp.Body["follower_count"] != float64(1) {
+		t.Fatalf("followers: status %d: %v", resp.Code, resp.Body)
+	}
+	if followers := resp.Body["followers"].([]any); len(followers) != 1 || followers[0].(map[string]any)["username"] != "bob" {
+		t.Errorf("followers %v, want only bob", followers)
+	}
+
+	resp = handlertest.Do(t, e, "GET", "/users/"+strconv.Itoa(bob.ID)+"/following", "", bearer(bobToken)...)
+	if resp.Code != 200 || resp.Body["following_count"] != float64(1) {
+		t.Fatalf("following: status %d: %v", resp.Code, resp.Body)
+	}
+	if following := resp.Body["following"].([]any); len(following) != 1 || following[0].(map[string]any)["username"] != "alice" {
+		t.Errorf("following %v, want only alice", following)
+	}
+
+	if resp := handlertest.Do(t, e, "POST", "/users/99/follow", "", bearer(bobToken)...); resp.Code != 404 {
+		t.Errorf("follow missing user: status %d, want 404", resp.Code)
+	}
+	if resp := handlertest.Do(t, e, "POST", "/users/"+strconv.Itoa(bob.ID)+"/follow", "", bearer(bobToken)...); resp.Code != 400 {
+		t.Errorf("follow yourself: status %d, want 400", resp.Code)
+	}
+}
+
+func TestFollowDeletedUser(t *testing.T) {
+	store := memory.NewStore()
+	alice := handlertest.CreateUser(t, store, "alice")
+	handlertest.CreateUser(t, store, "bob")
+	carol := handlertest.CreateUser(t, store, "carol")
+	e := newServer(t, store)
+	aliceToken, _ := login(t, e, "alice")
+	bobToken, _ := login(t, e, "bob")
+	carolToken, _ := login(t, e, "carol")
+
+	for _, token := range []string{bobToken, carolToken} {
+		if resp := handlertest.Do(t, e, "POST", "/users/"+strconv.Itoa(alice.ID)+"/follow", "", bearer(token)...); resp.Code != 200 {
+			t.Fatalf("follow alice: status %d: %v", resp.Code, resp.Body)
+		}
+	}
+	if resp := handlertest.Do(t, e, "POST", "/users/"+strconv.Itoa(carol.ID)+"/follow", "", bearer(aliceToken)...); resp.Code != 200 {
+		t.Fatalf("follow carol: status %d: %v", resp.Code, resp.Body)
+	}
+	if err := store.Repositories().Users.SoftDelete(context.Background(), carol.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := handlertest.Do(t, e, "GET", "/users/"+strconv.Itoa(alice.ID)+"/followers", "", bearer(aliceToken)...)
+	if resp.Code != 200 {
+		t.Fatalf("followers: status %d: %v", resp.Code, resp.Body)
+	}
+	followers := resp.Body["followers"].([]any)
+	if len(followers) != 1 || followers[0].(map[string]any)["username"] != "bob" {
+		t.Errorf("followers %v, want only bob", followers)
+	}
+
+	for _, list := range []string{"followers", "following"} {
+		path := "/users/" + strconv.Itoa(carol.ID) + "/" + list
+		if resp := handlertest.Do(t, e, "GET", path, "", bearer(aliceToken)...); resp.Code != 404 {
+			t.Errorf("GET %s of a deleted user: status %d, want 404", path, resp.Code)
+		}
+	}
+
+	path := "/users/" + strconv.Itoa(carol.ID) + "/follow"
+	if resp := handlertest.Do(t, e, "POST", path, "", bearer(bobToken)...); resp.Code != 404 {
+		t.Errorf("follow deleted user: status %d, want 404: %v", resp.Code, resp.Body)
+	}
+	if resp := handlertest.Do(t, e, "DELETE", path, "", bearer(aliceToken)...); resp.Code != 200 || resp.Body["following"] != false {
+		t.Errorf("unfollow deleted user: status %d: %v", resp.Code, resp.Body)
+	}
+}
+
+func TestFollowYourself(t *testing.T) {
+	store := memory.NewStore()
+	alice := handlertest.CreateUser(t, store, "alice")
+	e := newServer(t, store)
+	access, _ := login(t, e, "alice")
+	path := "/users/" + strconv.Itoa(alice.ID) + "/follow"
+
+	tests := []struct {
+		method  string
+		message string
+	}{
+		{"POST", "you cannot follow yourself"},
+		{"DELETE", "you cannot unfollow yourself"},
+	}
+	for _, tt := range tests {
+		resp := handlertest.Do(t, e, tt.method, path, "", bearer(access)...)
+		if resp.Code != 400 || resp.Body["error"].(map[string]any)["message"] != tt.message {
+			t.Errorf("%s %s: status %d, body %v, want 400 %q", tt.method, path, resp.Code, resp.Body, tt.message)
+		}
+	}
+}
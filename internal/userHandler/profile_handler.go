@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"w3/gc3/internal/apperror"
+	"w3/gc3/internal/auth"
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UpdateProfileRequest struct, omitted fields are left unchanged
+type UpdateProfileRequest struct {
+	FullName  *string `json:"full_name" validate:"omitempty,full_name"`
+	Bio       *string `json:"bio" validate:"omitempty,max=300"`
+	AvatarURL *string `json:"avatar_url" validate:"omitempty,url_or_empty,max=255"` // empty string removes the avatar
+}
+
+// AccountResponse is the account of the requesting user
+type AccountResponse struct {
+	model.User
+	PostCount int `json:"post_count"`
+}
+
+// @Summary Get a user's profile
+// @Description Retrieve the public profile of a user with their post, follower and following counts, no authentication needed
+// @Tags Users
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} model.UserProfile "User profile"
+// @Failure 404 {object} apperror.Response "User not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /users/{username} [get]
+func (h *UserHandler) GetProfile(c echo.Context) error {
+	profile, err := h.users.GetProfile(c.Request().Context(), c.Param("username"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound("user not found")
+		}
+		return apperror.Internal(err, "failed to fetch user")
+	}
+	return c.JSON(http.StatusOK, profile)
+}
+
+// @Summary Get your account
+// @Description Retrieve the account of the authenticated user, including their email and age
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} AccountResponse "Your account"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /users/me [get]
+func (h *UserHandler) GetMe(c echo.Context) error {
+	principal, ok := auth.CurrentUser(c)
+	if !ok {
+		return apperror.Unauthorized("not authorized")
+	}
+
+	account, err := h.account(c, principal.UserID)
+	if err != nil {
+		return apperror.Internal(err, "failed to fetch user")
+	}
+	return c.JSON(http.StatusOK, account)
+}
+
+// @Summary Update your profile
+// @Description Update the full name, bio or avatar URL of the authenticated user; omitted fields are left unchanged and an empty avatar_url removes the avatar
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body UpdateProfileRequest true "Profile fields to change"
+// @Success 200 {object} AccountResponse "Profile updated"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /users/me [patch]
+func (h *UserHandler) UpdateMe(c echo.Context) error {
+	principal, ok := auth.CurrentUser(c)
+	if !ok {
+		return apperror.Unauthorized("not authorized")
+	}
+	userID := principal.UserID
+
+	req := new(UpdateProfileRequest)
+	if err := c.Bind(req); err != nil {
+		return apperror.BadRequest("invalid request body")
+	}
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+	if req.FullName == nil && req.Bio == nil && req.AvatarURL == nil {
+		return apperror.BadRequest("nothing to update")
+	}
+
+	// save the profile and log the activity in one transaction
+	ctx := c.Request().Context()
+	err := h.uow.Do(ctx, func(repos repository.Repositories) error {
+		user, err := repos.Users.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if req.FullName != nil {
+			user.FullName = strings.TrimSpace(*req.FullName)
+		}
+		if req.Bio != nil {
+			user.Bio = *req.Bio
+		}
+		if req.AvatarURL != nil {
+			user.AvatarURL = *req.AvatarURL
+		}
+		if err := repos.Users.UpdateProfile(ctx, user); err != nil {
+			return err
+		}
+		return repos.Activities.Log(ctx, userID, "User updated their profile")
+	})
+	if err != nil {
+		return apperror.Internal(err, "failed to update profile")
+	}
+
+	account, err := h.account(c, userID)
+	if err != nil {
+		return apperror.Internal(err, "failed to fetch user")
+	}
+	return c.JSON(http.StatusOK, account)
+}
+
+// account reads the account of a user together with their post count
+func (h *UserHandler) account(c echo.Context, userID int) (*AccountResponse, error) {
+	ctx := c.Request().Context()
+	user, err := h.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := h.users.GetProfile(ctx, user.Username)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountResponse{User: *user, PostCount: profile.PostCount}, nil
+}
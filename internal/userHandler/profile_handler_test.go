@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"testing"
+
+	"w3/gc3/internal/handlertest"
+	"w3/gc3/internal/repository/memory"
+)
+
+func TestProfile(t *testing.T) {
+	store := memory.NewStore()
+	handlertest.CreateUser(t, store, "alice")
+	e := newServer(t, store)
+	access, _ := login(t, e, "alice")
+
+	resp := handlertest.Do(t, e, "PATCH", "/users/me", `{"full_name":"Alice Liddell","bio":"down the rabbit hole"}`, bearer(access)...)
+	if resp.Code != 200 || resp.Body["full_name"] != "Alice Liddell" || resp.Body["email"] != "alice@example.com" {
+		t.Fatalf("update: status %d: %v", resp.Code, resp.Body)
+	}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"nothing to update", `{}`},
+		{"blank full name", `{"full_name":"  "}`},
+		{"invalid avatar", `{"avatar_url":"alice.png"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp := handlertest.Do(t, e, "PATCH", "/users/me", tt.body, bearer(access)...); resp.Code != 400 {
+				t.Errorf("status %d, want 400: %v", resp.Code, resp.Body)
+			}
+		})
+	}
+
+	resp = handlertest.Do(t, e, "GET", "/users/me", "", bearer(access)...)
+	if resp.Code != 200 || resp.Body["bio"] != "down the rabbit hole" || resp.Body["post_count"] != float64(0) {
+		t.Errorf("me: status %d: %v", resp.Code, resp.Body)
+	}
+
+	// the public profile needs no token and leaves out the private fields
+	resp = handlertest.Do(t, e, "GET", "/users/alice", "")
+	if resp.Code != 200 || resp.Body["full_name"] != "Alice Liddell" {
+		t.Fatalf("profile: status %d: %v", resp.Code, resp.Body)
+	}
+	if _, ok := resp.Body["email"]; ok {
+		t.Errorf("profile exposes the email: %v", resp.Body)
+	}
+	if resp := handlertest.Do(t, e, "GET", "/users/nobody", ""); resp.Code != 404 {
+		t.Errorf("missing user: status %d, want 404", resp.Code)
+	}
+}
+
+func TestUpdateMeAvatar(t *testing.T) {
+	store := memory.NewStore()
+	handlertest.CreateUser(t, store, "alice")
+	e := newServer(t, store)
+	access, _ := login(t, e, "alice")
+
+	tests := []struct {
+		name   string
+		body   string
+		code   int
+		avatar string
+	}{
+		{"set", `{"avatar_url":"https://example.com/alice.png"}`, 200, "https://example.com/alice.png"},
+		{"invalid", `{"avatar_url":"alice.png"}`, 400, ""},
+		{"omitted is left unchanged", `{"bio":"hello"}`, 200, "https://example.com/alice.png"},
+		{"empty removes it", `{"avatar_url":""}`, 200, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := handlertest.Do(t, e, "PATCH", "/users/me", tt.body, bearer(access)...)
+			if resp.Code != tt.code {
+				t.Fatalf("status %d, want %d: %v", resp.Code, tt.code, resp.Body)
+			}
+			if tt.code == 200 && resp.Body["avatar_url"] != tt.avatar {
+				t.Errorf("avatar_url %q, want %q", resp.Body["avatar_url"], tt.avatar)
+			}
+		})
+	}
+}
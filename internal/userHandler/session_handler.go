@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+
+	"w3/gc3/internal/apperror"
+	"w3/gc3/internal/auth"
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository"
+	"w3/gc3/internal/token"
+)
+
+// refresh request struct
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// @Summary Refresh the access token
+// @Description Exchange a refresh token for a new access token and a new refresh token. Each refresh token can be used once; replaying one revokes every token of its session.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body RefreshRequest true "Refresh token"
+// @Success 200 {object} LoginResponse "Tokens rotated"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Invalid, expired or reused refresh token"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /users/refresh [post]
+func (h *UserHandler) Refresh(c echo.Context) error {
+	var req RefreshRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.BadRequest("invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	var resp *LoginResponse
+	var rejected string
+
+	err := h.uow.Do(ctx, func(repos repository.Repositories) error {
+		stored, err := repos.RefreshTokens.GetByHash(ctx, token.HashRefreshToken(req.RefreshToken))
+		if errors.Is(err, repository.ErrNotFound) {
+			rejected = "invalid refresh token"
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if stored.RevokedAt != nil {
+			rejected = "refresh token has been revoked"
+			return nil
+		}
+
+		// a rotated token coming back means it leaked: kill the whole family
+		if stored.UsedAt != nil {
+			rejected = "refresh token reuse detected, please login again"
+			if err := repos.RefreshTokens.RevokeFamily(ctx, stored.FamilyID); err != nil {
+				return err
+			}
+			return repos.Activities.Log(ctx, stored.UserID, "Refresh token reuse detected, all sessions of the token family revoked")
+		}
+
+		if time.Now().After(stored.ExpiresAt) {
+			rejected = "refresh token expired"
+			return nil
+		}
+
+		if err := repos.RefreshTokens.MarkUsed(ctx, stored.ID); err != nil {
+			return err
+		}
+		resp, err = h.issueTokens(ctx, repos, stored.UserID, stored.FamilyID)
+		return err
+	})
+	if err != nil {
+		return apperror.Internal(err, "failed to refresh token")
+	}
+	if rejected != "" {
+		return apperror.Unauthorized(rejected)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// @Summary Logout
+// @Description Revoke the access token of the request and the refresh tokens of its session
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} map[string]string "Logged out"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /users/logout [post]
+func (h *UserHandler) Logout(c echo.Context) error {
+	principal, ok := auth.CurrentUser(c)
+	if !ok {
+		return apperror.Unauthorized("not authorized")
+	}
+
+	ctx := c.Request().Context()
+	err := h.uow.Do(ctx, func(repos repository.Repositories) error {
+		if err := repos.Sessions.PurgeExpired(ctx); err != nil {
+			return err
+		}
+		if err := repos.Sessions.RevokeAccessToken(ctx, principal.TokenID, principal.UserID, principal.ExpiresAt); err != nil {
+			return err
+		}
+		if principal.SessionID != "" {
+			if err := repos.RefreshTokens.RevokeFamily(ctx, principal.SessionID); err != nil {
+				return err
+			}
+		}
+		return repos.Activities.Log(ctx, principal.UserID, "User logged out")
+	})
+	if err != nil {
+		return apperror.Internal(err, "failed to log out")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "logged out successfully"})
+}
+
+// @Summary Logout everywhere
+// @Description Revoke every access and refresh token of the logged-in user
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} map[string]string "Logged out of every session"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /users/logout-all [post]
+func (h *UserHandler) LogoutAll(c echo.Context) error {
+	principal, ok := auth.CurrentUser(c)
+	if !ok {
+		return apperror.Unauthorized("not authorized")
+	}
+	userID := principal.UserID
+
+	ctx := c.Request().Context()
+	err := h.uow.Do(ctx, func(repos repository.Repositories) error {
+		if err := repos.Sessions.RevokeAll(ctx, userID); err != nil {
+			return err
+		}
+		if err := repos.RefreshTokens.RevokeAllForUser(ctx, userID); err != nil {
+			return err
+		}
+		return repos.Activities.Log(ctx, userID, "User logged out of all sessions")
+	})
+	if err != nil {
+		return apperror.Internal(err, "failed to log out")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "logged out of all sessions successfully"})
+}
+
+// issueTokens signs an access token for userID and stores a new refresh token in familyID
+func (h *UserHandler) issueTokens(ctx context.Context, repos repository.Repositories, userID int, familyID string) (*LoginResponse, error) {
+	now := time.Now()
+
+	user, err := repos.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// tokens carry the user's token version so logout-all can invalidate them
+	version, err := repos.Sessions.TokenVersion(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	tokenID, err := token.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, hash, err := token.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	err = repos.RefreshTokens.Create(ctx, &model.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: now.Add(h.tokens.RefreshTTL()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// create new jwt claims
+	claims := &token.Claims{
+		UserID:    user.ID,
+		Username:  user.Username,
+		Roles:     auth.RolesFor(user.Role),
+		Version:   version,
+		SessionID: familyID, // ties the access token to its refresh token family
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(h.tokens.TTL())),
+		},
+	}
+	accessToken, err := h.tokens.Sign(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(h.tokens.TTL().Seconds()),
+	}, nil
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"testing"
+
+	"w3/gc3/internal/handlertest"
+	"w3/gc3/internal/repository/memory"
+)
+
+func TestRefreshRotates(t *testing.T) {
+	store := memory.NewStore()
+	handlertest.CreateUser(t, store, "alice")
+	e := newServer(t, store)
+	_, first := login(t, e, "alice")
+
+	resp := handlertest.Do(t, e, "POST", "/users/refresh", `{"refresh_token":"`+first+`"}`)
+	if resp.Code != 200 {
+		t.Fatalf("refresh: status %d: %v", resp.Code, resp.Body)
+	}
+	second := resp.Body["refresh_token"].(string)
+	if second == first {
+		t.Fatal("refresh returned the same refresh token")
+	}
+	claims, err := handlertest.NewTokens(t).Parse(resp.Body["access_token"].(string))
+	if err != nil || claims.Username != "alice" {
+		t.Errorf("new access token: %v %v", claims, err)
+	}
+
+	if resp := handlertest.Do(t, e, "POST", "/users/refresh", `{"refresh_token":"unknown"}`); resp.Code != 401 {
+		t.Errorf("unknown refresh token: status %d, want 401", resp.Code)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	store := memory.NewStore()
+	handlertest.CreateUser(t, store, "alice")
+	e := newServer(t, store)
+	_, first := login(t, e, "alice")
+	_, other := login(t, e, "alice") // another session, in another family
+
+	resp := handlertest.Do(t, e, "POST", "/users/refresh", `{"refresh_token":"`+first+`"}`)
+	if resp.Code != 200 {
+		t.Fatalf("refresh: status %d: %v", resp.Code, resp.Body)
+	}
+	second := resp.Body["refresh_token"].(string)
+
+	// the rotated token coming back means it leaked
+	resp = handlertest.Do(t, e, "POST", "/users/refresh", `{"refresh_token":"`+first+`"}`)
+	if resp.Code != 401 {
+		t.Fatalf("reused token: status %d, want 401", resp.Code)
+	}
+	// so the token it was rotated into is revoked with the rest of its family
+	resp = handlertest.Do(t, e, "POST", "/users/refresh", `{"refresh_token":"`+second+`"}`)
+	if resp.Code != 401 {
+		t.Errorf("token of the reused family: status %d, want 401", resp.Code)
+	}
+	// while the other session carries on
+	resp = handlertest.Do(t, e, "POST", "/users/refresh", `{"refresh_token":"`+other+`"}`)
+	if resp.Code != 200 {
+		t.Errorf("token of another family: status %d, want 200: %v", resp.Code, resp.Body)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	store := memory.NewStore()
+	handlertest.CreateUser(t, store, "alice")
+	e := newServer(t, store)
+	access, refresh := login(t, e, "alice")
+	otherAccess, otherRefresh := login(t, e, "alice")
+
+	if resp := handlertest.Do(t, e, "POST", "/users/logout", "", bearer(access)...); resp.Code != 200 {
+		t.Fatalf("logout: status %d: %v", resp.Code, resp.Body)
+	}
+
+	// the access token and the refresh tokens of its session are revoked
+	if resp := handlertest.Do(t, e, "POST", "/users/logout", "", bearer(access)...); resp.Code != 401 {
+		t.Errorf("revoked access token: status %d, want 401", resp.Code)
+	}
+	if resp := handlertest.Do(t, e, "POST", "/users/refresh", `{"refresh_token":"`+refresh+`"}`); resp.Code != 401 {
+		t.Errorf("refresh token of the session: status %d, want 401", resp.Code)
+	}
+
+	// the other session is left alone
+	resp := handlertest.Do(t, e, "POST", "/users/refresh", `{"refresh_token":"`+otherRefresh+`"}`)
+	if resp.Code != 200 {
+		t.Errorf("refresh token of another session: status %d, want 200: %v", resp.Code, resp.Body)
+	}
+	if resp := handlertest.Do(t, e, "POST", "/users/logout", "", bearer(otherAccess)...); resp.Code != 200 {
+		t.Errorf("access token of another session: status %d, want 200: %v", resp.Code, resp.Body)
+	}
+}
+
+func TestLogoutAll(t *testing.T) {
+	store := memory.NewStore()
+	handlertest.CreateUser(t, store, "alice")
+	e := newServer(t, store)
+	access, _ := login(t, e, "alice")
+	otherAccess, otherRefresh := login(t, e, "alice")
+
+	if resp := handlertest.Do(t, e, "POST", "/users/logout-all", "", bearer(access)...); resp.Code != 200 {
+		t.Fatalf("logout-all: status %d: %v", resp.Code, resp.Body)
+	}
+	if resp := handlertest.Do(t, e, "POST", "/users/logout", "", bearer(otherAccess)...); resp.Code != 401 {
+		t.Errorf("access token of another session: status %d, want 401", resp.Code)
+	}
+	if resp := handlertest.Do(t, e, "POST", "/users/refresh", `{"refresh_token":"`+otherRefresh+`"}`); resp.Code != 401 {
+		t.Errorf("refresh token of another session: status %d, want 401", resp.Code)
+	}
+
+	// logging in again starts afresh
+	access, _ = login(t, e, "alice")
+	if resp := handlertest.Do(t, e, "POST", "/users/logout", "", bearer(access)...); resp.Code != 200 {
+		t.Errorf("new session: status %d, want 200: %v", resp.Code, resp.Body)
+	}
+}
@@ -1,31 +1,21 @@
 package handler
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
-	config "w3/gc3/config/database"
-	
+
 	"context"
 	"time"
 
-	"github.com/golang-jwt/jwt/v4"
+	"w3/gc3/internal/apperror"
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository"
+	"w3/gc3/internal/token"
+
 	"github.com/labstack/echo/v4"
 	"golang.org/x/crypto/bcrypt"
-	"github.com/jackc/pgconn"
 )
 
-// Users struct
-type Users struct {
-	ID            int       `json:"id"`               // Primary key, auto-incremented
-	FullName      string    `json:"full_name"`        // Full name of the user
-	Email         string    `json:"email"`           // Email address, unique
-	Username      string    `json:"username"`        // Username, unique
-	Password      string    `json:"password"`        // Password for the account
-	Age           int       `json:"age"`             // Age of the user
-	LastLoginDate time.Time `json:"last_login_date"` // Date and time of the last login
-	JwtToken      string    `json:"jwt_token"`       // JWT token for authentication
-}
-
 // RegisterRequest struct
 type RegisterRequest struct {
 	FullName string `json:"full_name" validate:"required,full_name"` // Full name of the user
@@ -41,12 +31,29 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
-// login response: token
+// login response: short-lived access token plus the refresh token to renew it
 type LoginResponse struct {
-	Token string `json:"token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // access token lifetime in seconds
 }
 
-var jwtSecret = []byte("12345")
+// compared against on logins with an unknown email, at the cost of real password hashes
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not the password of anyone"), bcrypt.DefaultCost)
+
+// UserHandler serves the /users endpoints
+type UserHandler struct {
+	users  repository.UserRepository
+	uow    repository.UnitOfWork
+	tokens *token.Service
+	// how long a deleted account can be restored by logging in
+	gracePeriod time.Duration
+}
+
+func NewUserHandler(users repository.UserRepository, uow repository.UnitOfWork, tokens *token.Service, gracePeriod time.Duration) *UserHandler {
+	return &UserHandler{users: users, uow: uow, tokens: tokens, gracePeriod: gracePeriod}
+}
 
 // @Summary Register a new user
 // @Description Create a new user account by providing the required information
@@ -55,43 +62,51 @@ var jwtSecret = []byte("12345")
 // @Produce json
 // @Param request body RegisterRequest true "User registration data"
 // @Success 201 {object} map[string]interface{} "User created successfully"
-// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 400 {object} apperror.Response "Invalid input"
 // @Router /users/register [post]
-func Register(c echo.Context) error {
+func (h *UserHandler) Register(c echo.Context) error {
     var req RegisterRequest
     if err := c.Bind(&req); err != nil {
-        return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid Request"})
+        return apperror.BadRequest("invalid request body")
     }
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
 
 	// hash the password
     hashPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
     if err != nil {
-        return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Internal Server Error"})
+        return apperror.Internal(err, "failed to hash password")
     }
 
-    // queries to insert to both users and customers db
-	users_query := "INSERT INTO users (full_name, email, username, password, age) VALUES ($1, $2, $3, $4, $5) RETURNING id"
-	
-	var userID int
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	user := model.User{
+		FullName: req.FullName,
+		Email:    req.Email,
+		Username: req.Username,
+		Password: string(hashPassword),
+		Age:      req.Age,
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
 	defer cancel()
 
-	// query row 1: insert to users 
-	err = config.Pool.QueryRow(ctx, users_query, req.FullName, req.Email, req.Username, string(hashPassword), req.Age).Scan(&userID)
+	// insert to users and log the registration in one transaction
+	err = h.uow.Do(ctx, func(repos repository.Repositories) error {
+		if err := repos.Users.Create(ctx, &user); err != nil {
+			return err
+		}
+		return repos.Activities.Log(ctx, user.ID, "User registered")
+	})
 	if err != nil {
-		fmt.Println("Error inserting into users table:", err)
-
-		if pgErr, ok := err.(*pgconn.PgError); ok {
-			if pgErr.Code == "23505" { // Unique violation (email already registered)
-				return c.JSON(http.StatusBadRequest, map[string]string{"message": "Email already registered"})
-			}
+		if errors.Is(err, repository.ErrDuplicate) { // email or username already registered
+			return apperror.Conflict("email or username already registered")
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Internal Server Error"})
+		return apperror.Internal(err, "failed to register user")
 	}
 
-    return c.JSON(http.StatusOK, map[string]interface{}{
+    return c.JSON(http.StatusCreated, map[string]interface{}{
         "message": "User registered successfully",
-        "user_id": string(userID),
+        "user_id": user.ID,
         "email": req.Email,
     })
 }
@@ -102,39 +117,65 @@ func Register(c echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param request body LoginRequest true "User login data"
-// @Success 200 {object} map[string]interface{} "Authentication successful"
-// @Failure 401 {object} map[string]string "Unauthorized"
+// @Success 200 {object} LoginResponse "Authentication successful"
+// @Failure 400 {object} apperror.Response "Invalid input"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 500 {object} apperror.Response "Internal server error"
 // @Router /users/login [post]
-func Login(c echo.Context) error {
+func (h *UserHandler) Login(c echo.Context) error {
 	var req LoginRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message":"Invalid Request"})
+		return apperror.BadRequest("invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
 	}
 	
-	var user Users
-	query := "SELECT id, email, password FROM users WHERE email = $1"
-	err := config.Pool.QueryRow(context.Background(), query, req.Email).Scan(&user.ID, &user.Email, &user.Password)
+	user, err := h.users.GetByEmail(c.Request().Context(), req.Email)
+	if errors.Is(err, repository.ErrNotFound) {
+		// spend the time of a real comparison so unknown emails cannot be told apart
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(req.Password))
+		return apperror.Unauthorized("invalid email or password")
+	}
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid email or password"})
+		return apperror.Internal(err, "failed to fetch user")
 	}
 
 	// compare password to see if it matches the student password provided
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid email or password"})
+		return apperror.Unauthorized("invalid email or password")
+	}
+	// a deleted account only waits for the purger once its grace period is over
+	if user.DeletedAt != nil && time.Since(*user.DeletedAt) > h.gracePeriod {
+		return apperror.Unauthorized("invalid email or password")
 	}
 
-	// create new jwt claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     jwt.NewNumericDate(time.Now().Add(72 * time.Hour)), // Use `jwt.NewNumericDate` for expiry
-	})
-	
-	tokenString, err := token.SignedString(jwtSecret)
+	// start a new refresh token family for this session
+	familyID, err := token.NewFamilyID()
+	if err != nil {
+		return apperror.Internal(err, "failed to generate token")
+	}
 
+	var resp *LoginResponse
+	err = h.uow.Do(c.Request().Context(), func(repos repository.Repositories) error {
+		// logging in during the grace period restores a deleted account
+		if user.DeletedAt != nil {
+			if err := repos.Users.Restore(c.Request().Context(), user.ID); err != nil {
+				return err
+			}
+			if err := repos.Activities.Log(c.Request().Context(), user.ID, "User restored their account"); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		resp, err = h.issueTokens(c.Request().Context(), repos, user.ID, familyID)
+		return err
+	})
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Invalid Generate Token"})
+		return apperror.Internal(err, "failed to generate token")
 	}
 
 	// return ok status and login response
-	return c.JSON(http.StatusOK, LoginResponse{Token: tokenString})
+	return c.JSON(http.StatusOK, resp)
 }
\ No newline at end of file
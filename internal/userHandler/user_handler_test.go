@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"w3/gc3/internal/handlertest"
+	"w3/gc3/internal/middleware"
+	"w3/gc3/internal/model"
+	"w3/gc3/internal/repository"
+	"w3/gc3/internal/repository/memory"
+)
+
+// newServer serves the user routes the way main does, the protected ones
+// behind the JWT middleware
+func newServer(t *testing.T, store *memory.Store) *echo.Echo {
+	t.Helper()
+	repos := store.Repositories()
+	tokens := handlertest.NewTokens(t)
+	h := NewUserHandler(repos.Users, store, tokens, time.Hour)
+	jwt := middleware.JWTMiddleware(tokens, repos.Sessions)
+
+	e := handlertest.NewEcho()
+	e.POST("/users/register", h.Register)
+	e.POST("/users/login", h.Login)
+	e.POST("/users/refresh", h.Refresh)
+	e.POST("/users/logout", h.Logout, jwt)
+	e.POST("/users/logout-all", h.LogoutAll, jwt)
+	e.GET("/users/me", h.GetMe, jwt)
+	e.PATCH("/users/me", h.UpdateMe, jwt)
+	e.DELETE("/users/me", h.DeleteMe, jwt)
+	e.POST("/users/me/password", h.ChangePassword, jwt)
+	e.GET("/users/:username", h.GetProfile)
+	e.POST("/users/:id/follow", h.FollowUser, jwt)
+	e.DELETE("/users/:id/follow", h.UnfollowUser, jwt)
+	e.GET("/users/:id/followers", h.GetFollowers, jwt)
+	e.GET("/users/:id/following", h.GetFollowing, jwt)
+	return e
+}
+
+// login returns the access and refresh tokens of a user made by handlertest.CreateUser
+func login(t *testing.T, e *echo.Echo, username string) (access, refresh string) {
+	t.Helper()
+	resp := handlertest.Do(t, e, "POST", "/users/login", `{"email":"`+username+`@example.com","password":"`+handlertest.Password+`"}`)
+	if resp.Code != 200 {
+		t.Fatalf("login %s: status %d: %v", username, resp.Code, resp.Body)
+	}
+	return resp.Body["access_token"].(string), resp.Body["refresh_token"].(string)
+}
+
+func bearer(access string) []string {
+	return []string{echo.HeaderAuthorization, "Bearer " + access}
+}
+
+func TestRegister(t *testing.T) {
+	e := newServer(t, memory.NewStore())
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"valid", `{"full_name":"Alice Liddell","email":"alice@example.com","username":"alice","password":"secret123","age":30}`, 201},
+		{"same email", `{"full_name":"Alice Other","email":"alice@example.com","username":"alice2","password":"secret123","age":30}`, 409},
+		{"same username", `{"full_name":"Alice Other","email":"other@example.com","username":"alice","password":"secret123","age":30}`, 409},
+		{"weak password", `{"full_name":"Bob Builder","email":"bob@example.com","username":"bob","password":"password","age":30}`, 400},
+		{"missing age", `{"full_name":"Bob Builder","email":"bob@example.com","username":"bob","password":"secret123"}`, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := handlertest.Do(t, e, "POST", "/users/register", tt.body)
+			if resp.Code != tt.code {
+				t.Errorf("status %d, want %d: %v", resp.Code, tt.code, resp.Body)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	store := memory.NewStore()
+	handlertest.CreateUser(t, store, "alice")
+	e := newServer(t, store)
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		code     int
+	}{
+		{"valid", "alice@example.com", handlertest.Password, 200},
+		{"wrong password", "alice@example.com", "wrong123", 401},
+		{"unknown email", "nobody@example.com", handlertest.Password, 401},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := handlertest.Do(t, e, "POST", "/users/login", `{"email":"`+tt.email+`","password":"`+tt.password+`"}`)
+			if resp.Code != tt.code {
+				t.Errorf("status %d, want %d: %v", resp.Code, tt.code, resp.Body)
+			}
+		})
+	}
+}
+
+// unreachableUsers fails to look up users, as when the database is down
+type unreachableUsers struct {
+	repository.UserRepository
+}
+
+func (unreachableUsers) GetByEmail(context.Context, string) (*model.User, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestLoginStorageFailure(t *testing.T) {
+	store := memory.NewStore()
+	h := NewUserHandler(unreachableUsers{store.Repositories().Users}, store, handlertest.NewTokens(t), time.Hour)
+	e := handlertest.NewEcho()
+	e.POST("/users/login", h.Login)
+
+	// an outage is not a wrong password
+	resp := handlertest.Do(t, e, "POST", "/users/login", `{"email":"alice@example.com","password":"secret123"}`)
+	if resp.Code != 500 || resp.ErrorCode() != "internal" {
+		t.Errorf("status %d code %q, want 500 internal", resp.Code, resp.ErrorCode())
+	}
+}
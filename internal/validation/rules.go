@@ -0,0 +1,98 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// custom rules by tag
+var rules = map[string]validator.Func{
+	"username":  isUsername,
+	"password":  isPassword,
+	"full_name": isFullName,
+	"notblank":  isNotBlank,
+}
+
+// rules made of built-in ones, by tag
+var aliases = map[string]string{
+	// for optional URLs where "" clears the value, since omitempty on a
+	// pointer only skips nil
+	"url_or_empty": "eq=|url",
+}
+
+// usernames appear in URLs (GET /users/:username), so they stay URL-safe
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.]{2,29}$`)
+
+// isUsername accepts 3 to 30 letters, digits, underscores and dots starting with a letter
+func isUsername(fl validator.FieldLevel) bool {
+	return usernamePattern.MatchString(fl.Field().String())
+}
+
+// isPassword accepts 8 to 72 bytes (bcrypt ignores anything longer) with at
+// least one letter and one digit
+func isPassword(fl validator.FieldLevel) bool {
+	password := fl.Field().String()
+	if len(password) < 8 || len(password) > 72 {
+		return false
+	}
+	return strings.IndexFunc(password, unicode.IsLetter) >= 0 && strings.IndexFunc(password, unicode.IsDigit) >= 0
+}
+
+// isFullName accepts up to 100 characters of letters, spaces, apostrophes,
+// hyphens and dots, with at least one letter
+func isFullName(fl validator.FieldLevel) bool {
+	name := fl.Field().String()
+	if utf8.RuneCountInString(name) > 100 || strings.IndexFunc(name, unicode.IsLetter) < 0 {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsMark(r) && !strings.ContainsRune(" '’-.", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isNotBlank rejects strings made of whitespace only
+func isNotBlank(fl validator.FieldLevel) bool {
+	return strings.TrimSpace(fl.Field().String()) != ""
+}
+
+func newFieldError(fe validator.FieldError) FieldError {
+	code, message := describe(fe)
+	return FieldError{Field: fe.Field(), Code: code, Message: message}
+}
+
+// describe returns the code and message of a failed rule
+func describe(fe validator.FieldError) (code, message string) {
+	switch fe.Tag() {
+	case "required":
+		return "required", "is required"
+	case "notblank":
+		return "blank", "must not be blank"
+	case "email":
+		return "invalid_email", "must be a valid email address"
+	case "url", "url_or_empty":
+		return "invalid_url", "must be a valid URL"
+	case "min":
+		return "too_short", fmt.Sprintf("must be at least %s characters long", fe.Param())
+	case "max":
+		return "too_long", fmt.Sprintf("must be at most %s characters long", fe.Param())
+	case "gt":
+		return "too_small", "must be greater than " + fe.Param()
+	case "oneof":
+		return "not_allowed", "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "username":
+		return "invalid_username", "must be 3 to 30 letters, digits, underscores or dots and start with a letter"
+	case "password":
+		return "weak_password", "must be 8 to 72 characters long and contain a letter and a digit"
+	case "full_name":
+		return "invalid_full_name", "must be at most 100 characters of letters, spaces, apostrophes, hyphens or dots"
+	}
+	return "invalid", "is invalid"
+}
@@ -0,0 +1,75 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Validator checks request bodies against their `validate` tags, including the
+// custom rules of the application. It implements echo.Validator, handlers run
+// it through c.Validate.
+type Validator struct {
+	validate *validator.Validate
+}
+
+func New() *Validator {
+	v := validator.New()
+
+	// report fields by the name clients send them with
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	for tag, rule := range rules {
+		// only fails for an empty tag or a nil function
+		if err := v.RegisterValidation(tag, rule); err != nil {
+			panic(err)
+		}
+	}
+	for alias, tags := range aliases {
+		v.RegisterAlias(alias, tags)
+	}
+	return &Validator{validate: v}
+}
+
+// Validate returns Errors listing every invalid field of i, nil when it is valid
+func (v *Validator) Validate(i interface{}) error {
+	err := v.validate.Struct(i)
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return err
+	}
+
+	fieldErrors := make(Errors, len(invalid))
+	for n, fe := range invalid {
+		fieldErrors[n] = newFieldError(fe)
+	}
+	return fieldErrors
+}
+
+// FieldError describes why one field is invalid
+type FieldError struct {
+	Field   string `json:"field"`   // JSON name of the field
+	Code    string `json:"code"`    // machine-readable reason, e.g. "too_short"
+	Message string `json:"message"` // human-readable reason
+}
+
+// Errors are the invalid fields of a request
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	reasons := make([]string, len(e))
+	for i, fe := range e {
+		reasons[i] = fe.Field + " " + fe.Message
+	}
+	return strings.Join(reasons, ", ")
+}
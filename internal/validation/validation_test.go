@@ -0,0 +1,100 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type request struct {
+	Username string  `json:"username" validate:"omitempty,username"`
+	Password string  `json:"password" validate:"omitempty,password"`
+	FullName string  `json:"full_name" validate:"omitempty,full_name"`
+	Content  string  `json:"content" validate:"omitempty,notblank"`
+	Avatar   *string `json:"avatar" validate:"omitempty,url_or_empty"`
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name string
+		req  request
+		code string // empty when valid
+	}{
+		{"username", request{Username: "alice_1.x"}, ""},
+		{"username too short", request{Username: "al"}, "invalid_username"},
+		{"username too long", request{Username: "a" + strings.Repeat("b", 30)}, "invalid_username"},
+		{"username starting with a digit", request{Username: "1alice"}, "invalid_username"},
+		{"username with a slash", request{Username: "ali/ce"}, "invalid_username"},
+		{"password", request{Password: "secret123"}, ""},
+		{"password too short", request{Password: "abc1234"}, "weak_password"},
+		{"password too long", request{Password: strings.Repeat("a", 72) + "1"}, "weak_password"},
+		{"password without a digit", request{Password: "password"}, "weak_password"},
+		{"password without a letter", request{Password: "12345678"}, "weak_password"},
+		{"full name", request{FullName: "Anne-Marie O'Neil Jr."}, ""},
+		{"full name with accents", request{FullName: "José Müller"}, ""},
+		{"full name with digits", request{FullName: "R2 D2"}, "invalid_full_name"},
+		{"full name without letters", request{FullName: "--"}, "invalid_full_name"},
+		{"full name too long", request{FullName: strings.Repeat("a", 101)}, "invalid_full_name"},
+		{"content", request{Content: " hi "}, ""},
+		{"blank content", request{Content: " \t\n"}, "blank"},
+		{"url", request{Avatar: ptr("https://example.com/a.png")}, ""},
+		{"empty url clears it", request{Avatar: ptr("")}, ""},
+		{"invalid url", request{Avatar: ptr("not a url")}, "invalid_url"},
+	}
+
+	v := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Validate(&tt.req)
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var fields Errors
+			if !errors.As(err, &fields) || len(fields) != 1 {
+				t.Fatalf("err %v, want one field error", err)
+			}
+			if fields[0].Code != tt.code {
+				t.Errorf("code %q, want %q", fields[0].Code, tt.code)
+			}
+		})
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	type signup struct {
+		Email string `json:"email" validate:"required,email"`
+		Age   int    `json:"age" validate:"gt=0"`
+		Bio   string `validate:"max=3"`
+	}
+
+	err := New().Validate(&signup{Email: "nope", Age: 0, Bio: "toolong"})
+	var fields Errors
+	if !errors.As(err, &fields) {
+		t.Fatalf("err %v, want Errors", err)
+	}
+
+	// fields are named as clients send them, falling back to the Go name
+	want := []FieldError{
+		{Field: "email", Code: "invalid_email", Message: "must be a valid email address"},
+		{Field: "age", Code: "too_small", Message: "must be greater than 0"},
+		{Field: "Bio", Code: "too_long", Message: "must be at most 3 characters long"},
+	}
+	if len(fields) != len(want) {
+		t.Fatalf("fields %v, want %v", fields, want)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Errorf("field %d: %+v, want %+v", i, fields[i], want[i])
+		}
+	}
+	if got := fields.Error(); got != "email must be a valid email address, age must be greater than 0, Bio must be at most 3 characters long" {
+		t.Errorf("Error() = %q", got)
+	}
+}
@@ -1,12 +1,26 @@
 package main
 
 import (
+	"context"
+	"expvar"
+	"log"
+	"os"
+	"time"
+
 	config "w3/gc3/config/database"
+	"w3/gc3/config/settings"
 	user_handler "w3/gc3/internal/userHandler"
 	post_handler "w3/gc3/internal/postHandler"
 	comment_handler "w3/gc3/internal/commentHandler"
 	activity_handler "w3/gc3/internal/activityHandler"
+	"w3/gc3/internal/apperror"
+	"w3/gc3/internal/content"
+	admin_handler "w3/gc3/internal/adminHandler"
+	authz "w3/gc3/internal/auth"
 	cust_middleware "w3/gc3/internal/middleware"
+	"w3/gc3/internal/repository/postgres"
+	"w3/gc3/internal/token"
+	"w3/gc3/internal/validation"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -15,40 +29,121 @@ import (
 )
 
 func main(){
-	// migrate data to supabase
-	// config.MigrateData()
+	// load configuration from defaults, config file and environment
+	cfg, err := settings.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
 
 	// connect to db
-	config.InitDB()
+	config.InitDB(cfg.Database)
 	defer config.CloseDB()
 
+	// `migrate up|down [N]|status` manages the schema instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+	// `set-role <email> <role>` appoints moderators and admins
+	if len(os.Args) > 1 && os.Args[1] == "set-role" {
+		runSetRole(os.Args[2:])
+		return
+	}
+
+	// token signing keys
+	tokens, err := token.NewService(cfg.JWT)
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+	// where posts created without content get theirs
+	provider, err := content.New(cfg.Content, cfg.Outbound)
+	if err != nil {
+		log.Fatalf("Failed to load content provider: %v", err)
+	}
+	// storage and handlers
+	repos := postgres.NewRepositories(config.Pool)
+	auth := cust_middleware.JWTMiddleware(tokens, repos.Sessions)
+	uow := postgres.NewUnitOfWork(config.Pool)
+	users := user_handler.NewUserHandler(repos.Users, uow, tokens, time.Duration(cfg.Accounts.DeletionGracePeriod))
+	posts := post_handler.NewPostHandler(repos.Posts, repos.Comments, uow, provider)
+	comments := comment_handler.NewCommentHandler(repos.Comments, uow, cfg.Comments.MaxDepth)
+	activities := activity_handler.NewActivityHandler(repos.Activities)
+	admin := admin_handler.NewAdminHandler(repos.Users, uow)
+
+	// deleted accounts are removed for good once their grace period is over
+	go purgeDeletedUsers(context.Background(), repos.Users, cfg.Accounts)
+
 	e := echo.New()
+	e.Validator = validation.New()
+	e.HTTPErrorHandler = apperror.HTTPErrorHandler
 
+	e.Use(middleware.RequestID())
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 
 	// public routes
-	e.POST("users/register", user_handler.Register)
-	e.POST("users/login", user_handler.Login)
+	e.POST("users/register", users.Register)
+	e.POST("users/login", users.Login)
+	e.POST("users/refresh", users.Refresh)
+	e.GET("users/:username", users.GetProfile)
 
 	// protected routes //
+	// session
+	e.POST("users/logout", users.Logout, auth)
+	e.POST("users/logout-all", users.LogoutAll, auth)
+
+	// profiles
+	e.GET("users/me", users.GetMe, auth)
+	e.PATCH("users/me", users.UpdateMe, auth)
+	e.DELETE("users/me", users.DeleteMe, auth)
+	e.POST("users/me/password", users.ChangePassword, auth)
+
+	// follows
+	e.POST("users/:id/follow", users.FollowUser, auth)
+	e.DELETE("users/:id/follow", users.UnfollowUser, auth)
+	e.GET("users/:id/followers", users.GetFollowers, auth)
+	e.GET("users/:id/following", users.GetFollowing, auth)
+
 	// post
-	e.POST("posts", post_handler.CreatePost, cust_middleware.JWTMiddleware)
-	e.GET("posts", post_handler.GetAllPosts, cust_middleware.JWTMiddleware)
-	e.GET("posts/:id", post_handler.GetPostByID, cust_middleware.JWTMiddleware)
-	e.DELETE("posts/:id", post_handler.DeletePost, cust_middleware.JWTMiddleware)	
+	e.POST("posts", posts.CreatePost, auth)
+	e.GET("posts", posts.GetAllPosts, auth)
+	e.GET("feed", posts.GetFeed, auth)
+	e.GET("posts/:id", posts.GetPostByID, auth)
+	e.PATCH("posts/:id", posts.UpdatePost, auth)
+	e.PUT("posts/:id", posts.UpdatePost, auth)
+	e.GET("posts/:id/revisions", posts.GetPostRevisions, auth)
+	e.GET("posts/:id/comments", posts.GetPostComments, auth)
+	e.POST("posts/:id/like", posts.LikePost, auth)
+	e.DELETE("posts/:id/like", posts.UnlikePost, auth)
+	e.GET("posts/:id/likes", posts.GetPostLikes, auth)
+	e.DELETE("posts/:id", posts.DeletePost, auth)	
 
 	// comments
-	e.POST("/comments", comment_handler.CreateComment, cust_middleware.JWTMiddleware)
-	e.GET("/comments/:id", comment_handler.GetCommentByID, cust_middleware.JWTMiddleware)
-	e.DELETE("/comments/:id", comment_handler.DeleteCommentByID, cust_middleware.JWTMiddleware)
+	e.POST("/comments", comments.CreateComment, auth)
+	e.GET("/comments/:id", comments.GetCommentByID, auth)
+	e.PATCH("/comments/:id", comments.UpdateComment, auth)
+	e.GET("/comments/:id/revisions", comments.GetCommentRevisions, auth)
+	e.GET("/comments/:id/replies", comments.GetReplies, auth)
+	e.DELETE("/comments/:id", comments.DeleteCommentByID, auth)
+	e.POST("/comments/:id/like", comments.LikeComment, auth)
+	e.DELETE("/comments/:id/like", comments.UnlikeComment, auth)
+	e.POST("/comments/:id/reactions", comments.AddReaction, auth)
+	e.DELETE("/comments/:id/reactions/:reaction", comments.RemoveReaction, auth)
 
 	// activity
-	e.GET("activities", activity_handler.GetActivities, cust_middleware.JWTMiddleware)
+	e.GET("activities", activities.GetActivities, auth)
+
+	// admin
+	manageUsers := authz.Require(authz.ManageUsers)
+	e.GET("admin/users", admin.ListUsers, auth, manageUsers)
+	e.PATCH("admin/users/:id/role", admin.UpdateRole, auth, manageUsers)
+	e.DELETE("admin/users/:id", admin.DeleteUser, auth, manageUsers)
+	// runtime and third-party API metrics, as published with expvar
+	e.GET("admin/metrics", echo.WrapHandler(expvar.Handler()), auth, authz.Require(authz.ViewMetrics))
 
 	// swagger
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
-	// start the server at 8080
-	e.Logger.Fatal(e.Start(":8080"))
+	// start the server on the configured port
+	e.Logger.Fatal(e.Start(":" + cfg.Server.Port))
 }
\ No newline at end of file
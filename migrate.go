@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	config "w3/gc3/config/database"
+)
+
+const migrateUsage = `usage: gc3 migrate <command>
+
+commands:
+  up          apply every pending migration
+  down [N]    roll back the latest N migrations (default 1)
+  status      list migrations and whether they are applied`
+
+// runMigrate handles `gc3 migrate ...` against the already connected pool
+func runMigrate(args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := config.MigrateUp(ctx, config.Pool)
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				log.Fatalf("invalid number of steps %q", args[1])
+			}
+			steps = n
+		}
+		rolledBack, err := config.MigrateDown(ctx, config.Pool, steps)
+		for _, m := range rolledBack {
+			fmt.Printf("rolled back %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("nothing to roll back")
+		}
+
+	case "status":
+		statuses, err := config.MigrationStatuses(ctx, config.Pool)
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, state)
+		}
+
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"w3/gc3/config/settings"
+	"w3/gc3/internal/repository"
+)
+
+// purgeDeletedUsers removes the accounts whose deletion grace period is over,
+// every cfg.PurgeInterval until ctx is done
+func purgeDeletedUsers(ctx context.Context, users repository.UserRepository, cfg settings.AccountsConfig) {
+	ticker := time.NewTicker(time.Duration(cfg.PurgeInterval))
+	defer ticker.Stop()
+
+	for {
+		purged, err := users.PurgeDeleted(ctx, time.Now().Add(-time.Duration(cfg.DeletionGracePeriod)))
+		if err != nil {
+			log.Printf("Failed to purge deleted users: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d deleted users", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	config "w3/gc3/config/database"
+	authz "w3/gc3/internal/auth"
+	"w3/gc3/internal/repository/postgres"
+)
+
+const setRoleUsage = `usage: gc3 set-role <email> <user|moderator|admin>`
+
+// runSetRole handles `gc3 set-role ...`, the way to appoint the first admin
+func runSetRole(args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if len(args) != 2 || !authz.ValidRole(args[1]) {
+		log.Fatal(setRoleUsage)
+	}
+
+	repos := postgres.NewRepositories(config.Pool)
+	user, err := repos.Users.GetByEmail(ctx, args[0])
+	if err != nil {
+		log.Fatalf("Failed to find user %s: %v", args[0], err)
+	}
+	if err := repos.Users.UpdateRole(ctx, user.ID, args[1]); err != nil {
+		log.Fatalf("Failed to update role: %v", err)
+	}
+	// existing tokens still carry the old roles
+	if err := repos.Sessions.RevokeAll(ctx, user.ID); err != nil {
+		log.Fatalf("Failed to revoke sessions: %v", err)
+	}
+	fmt.Printf("%s is now %s\n", user.Email, args[1])
+}